@@ -1,13 +1,24 @@
 package main
 
 import (
+	"encoding/base64"
+	"fmt"
 	"log"
+	"math"
 	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/infrastructure/breach"
+	"github.com/prakoso-id/go-windsurf/internal/infrastructure/mail"
 	"github.com/prakoso-id/go-windsurf/internal/infrastructure/middleware"
+	"github.com/prakoso-id/go-windsurf/internal/infrastructure/oidc"
 	"github.com/prakoso-id/go-windsurf/internal/infrastructure/persistence"
 	"github.com/prakoso-id/go-windsurf/internal/interfaces/handlers"
 )
@@ -27,46 +38,256 @@ func main() {
 	// Initialize repositories
 	userRepo := persistence.NewUserRepository(db)
 	productRepo := persistence.NewProductRepository(db)
+	categoryRepo := persistence.NewCategoryRepository(db)
+	refreshTokenRepo := persistence.NewRefreshTokenRepository(db)
+	signingKeyRepo := persistence.NewSigningKeyRepository(db)
+	patRepo := persistence.NewPersonalAccessTokenRepository(db)
+	apiKeyRepo := persistence.NewAPIKeyRepository(db)
+	mfaRepo := persistence.NewMFARepository(db)
+	passwordResetRepo := persistence.NewPasswordResetTokenRepository(db)
+	loginThrottleRepo := persistence.NewLoginThrottleRepository(db)
+	auditEventRepo := persistence.NewAuditEventRepository(db)
+	sessionRepo := persistence.NewSessionRepository(db)
+	oidcStateRepo := persistence.NewOIDCLoginStateRepository(db)
+	userIdentityRepo := persistence.NewUserIdentityRepository(db)
+	oauthClientRepo := persistence.NewOAuthClientRepository(db)
+	oauthCodeRepo := persistence.NewOAuthAuthorizationCodeRepository(db)
+	oauthConsentRepo := persistence.NewOAuthConsentRepository(db)
+	emailChangeRepo := persistence.NewEmailChangeRepository(db)
+	denylistRepo := persistence.NewCachedTokenDenylistRepository(persistence.NewTokenDenylistRepository(db), 30*time.Second)
 
 	// Initialize services
-	authService := services.NewAuthService(os.Getenv("JWT_SECRET"))
-	userService := services.NewUserService(userRepo)
-	productService := services.NewProductService(productRepo)
+	keyManager, err := services.NewKeyManager(
+		signingKeyRepo,
+		requireEnvBase64("JWT_KEY_ENCRYPTION_KEY"),
+		getEnv("JWT_SIGNING_ALGORITHM", services.AlgorithmRS256),
+		getEnvDuration("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour),
+		getEnvDuration("JWT_KEY_GRACE_PERIOD", 24*time.Hour),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	keyManager.StartRotation(time.Minute)
+
+	mailer, err := newMailer()
+	if err != nil {
+		log.Fatal(err)
+	}
+	appBaseURL := getEnv("APP_BASE_URL", "http://localhost:8080")
+
+	passwordHasher, err := services.NewPasswordHasher(services.Argon2Params{
+		Memory:      uint32(getEnvIntInRange("PASSWORD_HASH_MEMORY_KIB", int(services.DefaultArgon2Params.Memory), 1, math.MaxUint32)),
+		Iterations:  uint32(getEnvIntInRange("PASSWORD_HASH_ITERATIONS", int(services.DefaultArgon2Params.Iterations), 1, math.MaxUint32)),
+		Parallelism: uint8(getEnvIntInRange("PASSWORD_HASH_PARALLELISM", int(services.DefaultArgon2Params.Parallelism), 1, math.MaxUint8)),
+		SaltLength:  services.DefaultArgon2Params.SaltLength,
+		KeyLength:   services.DefaultArgon2Params.KeyLength,
+	}, getEnvIntInRange("PASSWORD_HASH_CONCURRENCY", runtime.NumCPU(), 1, math.MaxInt32))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	passwordPolicy, err := newPasswordPolicy()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	authService := services.NewAuthService(keyManager, userRepo, refreshTokenRepo, denylistRepo, sessionRepo)
+	userService, err := services.NewUserService(userRepo, passwordResetRepo, authService, passwordHasher, passwordPolicy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	productService := services.NewProductService(productRepo, categoryRepo)
+	categoryService := services.NewCategoryService(categoryRepo)
+	patService := services.NewPersonalAccessTokenService(patRepo, authService)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo)
+	mfaService := services.NewMFAService(mfaRepo, userService, getEnv("MFA_ISSUER", "go-windsurf"))
+	passwordResetService := services.NewPasswordResetService(passwordResetRepo, userRepo, passwordHasher, passwordPolicy, authService, mailer, getEnv("PASSWORD_RESET_URL", appBaseURL+"/api/v1/reset-password"))
+	verificationService := services.NewEmailVerificationService(userRepo, authService, mailer, appBaseURL)
+	magicLinkService := services.NewMagicLinkService(userRepo, authService, mailer, getEnv("MAGIC_LINK_URL", appBaseURL+"/api/v1/login/magic-link/verify"))
+	auditService := services.NewAuditService(auditEventRepo)
+	loginGuard := services.NewLoginGuard(loginThrottleRepo, userRepo, auditService)
+	adminService := services.NewAdminService(userRepo, authService, passwordResetService, auditService)
+	oauthService := services.NewOAuthService(oauthClientRepo, oauthCodeRepo, oauthConsentRepo, userRepo, authService)
+	emailChangeService := services.NewEmailChangeService(emailChangeRepo, userRepo, passwordResetRepo, authService, auditService, mailer, appBaseURL)
+	accountService := services.NewAccountService(
+		userRepo,
+		userIdentityRepo,
+		mfaRepo,
+		sessionRepo,
+		patRepo,
+		apiKeyRepo,
+		oauthClientRepo,
+		oauthConsentRepo,
+		productRepo,
+		auditEventRepo,
+		authService,
+		auditService,
+		mailer,
+		getEnvDuration("ACCOUNT_DELETION_COOLING_OFF", 14*24*time.Hour),
+	)
+	accountService.StartPurge(time.Hour)
+	oidcService := services.NewOIDCService(loadIdentityProviders(appBaseURL), oidcStateRepo, userIdentityRepo, userRepo, passwordHasher)
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService, userService)
-	userHandler := handlers.NewUserHandler(userService)
+	authHandler := handlers.NewAuthHandler(authService, userService, mfaService, loginGuard, oidcService, magicLinkService)
+	userHandler := handlers.NewUserHandler(userService, verificationService)
 	productHandler := handlers.NewProductHandler(productService)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	jwksHandler := handlers.NewJWKSHandler(keyManager)
+	adminHandler := handlers.NewAdminHandler(adminService, loginGuard)
+	patHandler := handlers.NewPersonalAccessTokenHandler(patService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	mfaHandler := handlers.NewMFAHandler(mfaService, userService, loginGuard)
+	passwordResetHandler := handlers.NewPasswordResetHandler(passwordResetService)
+	verificationHandler := handlers.NewEmailVerificationHandler(verificationService)
+	sessionHandler := handlers.NewSessionHandler(authService)
+	oauthHandler := handlers.NewOAuthHandler(oauthService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	emailChangeHandler := handlers.NewEmailChangeHandler(emailChangeService)
 
 	// Initialize router
 	r := gin.Default()
 
+	// Login lockouts are keyed by client IP, so X-Forwarded-For is only
+	// honoured when it comes from a configured proxy
+	var trustedProxies []string
+	if value := os.Getenv("TRUSTED_PROXIES"); value != "" {
+		trustedProxies = strings.Split(value, ",")
+	}
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatal(err)
+	}
+
+	r.GET("/.well-known/jwks.json", jwksHandler.JWKS)
+
 	// Public routes
 	api := r.Group("/api/v1")
 	{
 		api.POST("/login", authHandler.Login)
+		api.POST("/login/mfa", authHandler.LoginMFA)
+		api.POST("/login/magic-link", authHandler.RequestMagicLink)
+		api.GET("/login/magic-link/verify", authHandler.MagicLinkLogin)
+		api.POST("/login/magic-link/verify", authHandler.MagicLinkLogin)
+		api.GET("/oidc/:provider/login", authHandler.OIDCLogin)
+		api.GET("/oidc/:provider/callback", authHandler.OIDCCallback)
+		api.POST("/token/refresh", authHandler.RefreshToken)
 		api.POST("/register", userHandler.Register)
+		api.POST("/forgot-password", passwordResetHandler.ForgotPassword)
+		api.GET("/reset-password", passwordResetHandler.CheckResetToken)
+		api.POST("/reset-password", passwordResetHandler.ResetPassword)
+		api.GET("/verify-email", verificationHandler.VerifyEmail)
+		api.GET("/confirm-email-change", emailChangeHandler.ConfirmChange)
+		api.GET("/revert-email-change", emailChangeHandler.RevertChange)
+
+		// OAuth2 endpoints called by third-party clients
+		api.POST("/oauth/token", oauthHandler.Token)
+		api.POST("/oauth/introspect", oauthHandler.Introspect)
+		api.POST("/oauth/revoke", oauthHandler.Revoke)
 
 		// Protected routes
 		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware(authService))
+		protected.Use(middleware.AuthMiddleware(authService, apiKeyService), middleware.AuditImpersonation(auditService))
 		{
+			// Tokens issued to OAuth clients can use the product API only
+			firstParty := middleware.DenyClientTokens()
+			// Admins impersonating a user can look but not change credentials
+			notImpersonated := middleware.DenyImpersonation()
+			// Taking over the account must need more than a valid session
+			recentlyAuthenticated := middleware.RequireRecentAuth(services.ReauthenticationTTL)
+
+			protected.POST("/logout", firstParty, authHandler.Logout)
+			protected.POST("/logout/all", firstParty, authHandler.LogoutAll)
+
+			protected.POST("/auth/reauthenticate", firstParty, notImpersonated, authHandler.Reauthenticate)
+
+			protected.GET("/oauth/authorize", firstParty, notImpersonated, oauthHandler.Authorize)
+			protected.POST("/oauth/authorize", firstParty, notImpersonated, oauthHandler.Decide)
+
 			// User routes
 			users := protected.Group("/users")
+			users.Use(firstParty)
 			{
 				users.GET("/profile", userHandler.GetProfile)
 				users.PUT("/profile", userHandler.UpdateProfile)
-				users.POST("/change-password", userHandler.ChangePassword)
+				users.POST("/change-password", notImpersonated, recentlyAuthenticated, userHandler.ChangePassword)
+				users.POST("/change-email", notImpersonated, recentlyAuthenticated, emailChangeHandler.RequestChange)
+				users.POST("/verify-email/resend", verificationHandler.ResendVerification)
+
+				users.GET("/export", notImpersonated, accountHandler.ExportData)
+				users.POST("/deletion", notImpersonated, recentlyAuthenticated, accountHandler.ScheduleDeletion)
+				users.DELETE("/deletion", notImpersonated, accountHandler.CancelDeletion)
+
+				users.GET("/sessions", sessionHandler.ListSessions)
+				users.DELETE("/sessions/:id", sessionHandler.RevokeSession)
+
+				users.POST("/tokens", notImpersonated, recentlyAuthenticated, patHandler.CreateToken)
+				users.GET("/tokens", patHandler.ListTokens)
+				users.DELETE("/tokens/:id", patHandler.RevokeToken)
+
+				users.POST("/api-keys", notImpersonated, recentlyAuthenticated, apiKeyHandler.CreateAPIKey)
+				users.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+				users.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+
+				users.POST("/oauth-clients", notImpersonated, recentlyAuthenticated, oauthHandler.RegisterClient)
+				users.GET("/oauth-clients", oauthHandler.ListClients)
+				users.DELETE("/oauth-clients/:id", oauthHandler.DeleteClient)
+				users.GET("/consents", oauthHandler.ListConsents)
+				users.DELETE("/consents/:client_id", oauthHandler.RevokeConsent)
+
+				users.GET("/2fa", mfaHandler.GetStatus)
+				users.POST("/2fa/enroll", notImpersonated, mfaHandler.Enroll)
+				users.POST("/2fa/confirm", notImpersonated, mfaHandler.Confirm)
+				users.POST("/2fa/recovery-codes", notImpersonated, recentlyAuthenticated, mfaHandler.RegenerateRecoveryCodes)
+				users.POST("/2fa/disable", notImpersonated, recentlyAuthenticated, mfaHandler.Disable)
 			}
 
+			// Unverified users can still manage their own account, but need a
+			// verified email for everything else when REQUIRE_VERIFIED_EMAIL is set
+			verified := protected.Group("/")
+			if getEnvBool("REQUIRE_VERIFIED_EMAIL", false) {
+				verified.Use(middleware.RequireVerifiedEmail())
+			}
+
+			// Token scopes are bounded by the user's role, so checking the
+			// scope enforces both the role and any narrower token grant
+			canRead := middleware.RequireScope(models.PermissionProductsRead)
+			canWrite := middleware.RequireScope(models.PermissionProductsWrite)
+
 			// Product routes
-			products := protected.Group("/products")
+			products := verified.Group("/products")
+			{
+				products.POST("/", canWrite, productHandler.CreateProduct)
+				products.GET("/search", canRead, productHandler.SearchProducts)
+				products.GET("/autocomplete", canRead, productHandler.Autocomplete)
+				products.GET("/:id", canRead, productHandler.GetProduct)
+				products.PUT("/:id", canWrite, productHandler.UpdateProduct)
+				products.DELETE("/:id", canWrite, productHandler.DeleteProduct)
+				products.GET("/", canRead, productHandler.GetAllProducts)
+			}
+
+			// Categories belong to the product catalogue and share its scopes
+			categories := verified.Group("/categories")
 			{
-				products.POST("/", productHandler.CreateProduct)
-				products.GET("/:id", productHandler.GetProduct)
-				products.PUT("/:id", productHandler.UpdateProduct)
-				products.DELETE("/:id", productHandler.DeleteProduct)
-				products.GET("/", productHandler.GetAllProducts)
+				categories.POST("/", canWrite, categoryHandler.CreateCategory)
+				categories.GET("/", canRead, categoryHandler.GetCategoryTree)
+				categories.GET("/:id", canRead, categoryHandler.GetCategory)
+				categories.PUT("/:id", canWrite, categoryHandler.UpdateCategory)
+				categories.DELETE("/:id", canWrite, categoryHandler.DeleteCategory)
+			}
+
+			// Admin routes
+			admin := verified.Group("/admin")
+			admin.Use(firstParty, middleware.RequireRole(models.RoleAdmin))
+			{
+				admin.GET("/users", adminHandler.ListUsers)
+				admin.GET("/users/:id", adminHandler.GetUser)
+				admin.PUT("/users/:id/role", adminHandler.UpdateUserRole)
+				admin.POST("/users/:id/disable", adminHandler.DisableUser)
+				admin.POST("/users/:id/enable", adminHandler.EnableUser)
+				admin.POST("/users/:id/force-password-reset", adminHandler.ForcePasswordReset)
+				admin.POST("/users/:id/unlock", adminHandler.UnlockUser)
+				admin.POST("/users/:id/impersonate", adminHandler.ImpersonateUser)
+				admin.DELETE("/users/:id", adminHandler.DeleteUser)
 			}
 		}
 	}
@@ -75,3 +296,145 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// loadIdentityProviders reads the OpenID Connect providers listed in
+// OIDC_PROVIDERS. Each provider is configured with OIDC_<NAME>_ISSUER,
+// OIDC_<NAME>_CLIENT_ID and OIDC_<NAME>_CLIENT_SECRET.
+func loadIdentityProviders(baseURL string) map[string]services.IdentityProvider {
+	providers := make(map[string]services.IdentityProvider)
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		config := oidc.Config{
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  baseURL + "/api/v1/oidc/" + name + "/callback",
+		}
+		if config.Issuer == "" || config.ClientID == "" {
+			log.Fatalf("%sISSUER and %sCLIENT_ID must be set", prefix, prefix)
+		}
+		providers[name] = oidc.NewProvider(config)
+	}
+	return providers
+}
+
+// newMailer picks the mail transport from MAIL_DRIVER: "smtp" for real
+// delivery, "file" to write messages to MAIL_DIR, or "memory" to discard them.
+func newMailer() (services.Mailer, error) {
+	from := getEnv("MAIL_FROM", "no-reply@go-windsurf.local")
+
+	switch driver := getEnv("MAIL_DRIVER", "file"); driver {
+	case "smtp":
+		return mail.NewSMTPMailer(
+			getEnv("SMTP_HOST", "localhost"),
+			getEnv("SMTP_PORT", "587"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			from,
+		), nil
+	case "file":
+		return mail.NewFileMailer(getEnv("MAIL_DIR", "tmp/mail"), from)
+	case "memory":
+		return mail.NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver %q", driver)
+	}
+}
+
+// newPasswordPolicy screens passwords against a breached password corpus
+// only when BREACHED_PASSWORDS_DIR is set.
+func newPasswordPolicy() (services.PasswordPolicy, error) {
+	config := services.PasswordPolicyConfig{
+		MinLength:           getEnvInt("PASSWORD_MIN_LENGTH", services.DefaultPasswordPolicyConfig.MinLength),
+		MaxLength:           getEnvInt("PASSWORD_MAX_LENGTH", services.DefaultPasswordPolicyConfig.MaxLength),
+		MinCharacterClasses: getEnvInt("PASSWORD_MIN_CHARACTER_CLASSES", services.DefaultPasswordPolicyConfig.MinCharacterClasses),
+	}
+	if value := os.Getenv("PASSWORD_DENYLIST"); value != "" {
+		config.Denylist = strings.Split(value, ",")
+	}
+
+	var breached services.BreachedPasswordChecker
+	if dir := os.Getenv("BREACHED_PASSWORDS_DIR"); dir != "" {
+		corpus, err := breach.NewCorpus(dir)
+		if err != nil {
+			return nil, err
+		}
+		breached = corpus
+	}
+
+	return services.NewPasswordPolicy(config, breached), nil
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Invalid boolean for %s: %v", key, err)
+	}
+	return b
+}
+
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Fatalf("Invalid integer for %s: %v", key, value)
+	}
+	return n
+}
+
+// getEnvIntInRange is getEnvInt for settings that must lie between min and
+// max, inclusive.
+func getEnvIntInRange(key string, fallback, min, max int) int {
+	n := getEnvInt(key, fallback)
+	if n < min || n > max {
+		log.Fatalf("%s must be between %d and %d, got %d", key, min, max, n)
+	}
+	return n
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid duration for %s: %v", key, err)
+	}
+	return duration
+}
+
+func requireEnvBase64(key string) []byte {
+	value := os.Getenv(key)
+	if value == "" {
+		log.Fatalf("%s must be set", key)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		log.Fatalf("Invalid base64 for %s: %v", key, err)
+	}
+	return decoded
+}
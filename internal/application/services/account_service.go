@@ -0,0 +1,267 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+var ErrDeletionNotScheduled = errors.New("account deletion is not scheduled")
+
+// AccountExport is everything held about a user, as returned to them on
+// request. Secrets such as password and token hashes are left out.
+type AccountExport struct {
+	ExportedAt           time.Time                    `json:"exported_at"`
+	Profile              *models.User                 `json:"profile"`
+	Identities           []models.UserIdentity        `json:"identities"`
+	TwoFactor            *models.TOTPCredential       `json:"two_factor,omitempty"`
+	Sessions             []models.Session             `json:"sessions"`
+	PersonalAccessTokens []models.PersonalAccessToken `json:"personal_access_tokens"`
+	APIKeys              []models.APIKey              `json:"api_keys"`
+	OAuthClients         []models.OAuthClient         `json:"oauth_clients"`
+	OAuthConsents        []models.OAuthConsent        `json:"oauth_consents"`
+	Products             []models.Product             `json:"products"`
+	AuditEvents          []models.AuditEvent          `json:"audit_events"`
+}
+
+// AccountService lets users take out their data and delete their account.
+type AccountService interface {
+	Export(userID string) (*AccountExport, error)
+	// ScheduleDeletion schedules the user's account for deletion once the
+	// cooling-off period has passed. Asking again keeps the original date.
+	ScheduleDeletion(userID string) (time.Time, error)
+	CancelDeletion(userID string) error
+	// PurgeDue deletes the accounts whose cooling-off period has passed.
+	// Dependent rows are removed by the database, products are unlinked and
+	// the user's audit events are anonymized. An account that fails to purge
+	// is retried on the next run without holding up the others.
+	PurgeDue() error
+	// StartPurge runs PurgeDue every interval.
+	StartPurge(interval time.Duration)
+}
+
+type accountService struct {
+	userRepo     repositories.UserRepository
+	identityRepo repositories.UserIdentityRepository
+	mfaRepo      repositories.MFARepository
+	sessionRepo  repositories.SessionRepository
+	patRepo      repositories.PersonalAccessTokenRepository
+	apiKeyRepo   repositories.APIKeyRepository
+	clientRepo   repositories.OAuthClientRepository
+	consentRepo  repositories.OAuthConsentRepository
+	productRepo  repositories.ProductRepository
+	auditRepo    repositories.AuditEventRepository
+	authService  AuthService
+	auditService AuditService
+	mailer       Mailer
+	coolingOff   time.Duration
+}
+
+func NewAccountService(
+	userRepo repositories.UserRepository,
+	identityRepo repositories.UserIdentityRepository,
+	mfaRepo repositories.MFARepository,
+	sessionRepo repositories.SessionRepository,
+	patRepo repositories.PersonalAccessTokenRepository,
+	apiKeyRepo repositories.APIKeyRepository,
+	clientRepo repositories.OAuthClientRepository,
+	consentRepo repositories.OAuthConsentRepository,
+	productRepo repositories.ProductRepository,
+	auditRepo repositories.AuditEventRepository,
+	authService AuthService,
+	auditService AuditService,
+	mailer Mailer,
+	coolingOff time.Duration,
+) AccountService {
+	return &accountService{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		mfaRepo:      mfaRepo,
+		sessionRepo:  sessionRepo,
+		patRepo:      patRepo,
+		apiKeyRepo:   apiKeyRepo,
+		clientRepo:   clientRepo,
+		consentRepo:  consentRepo,
+		productRepo:  productRepo,
+		auditRepo:    auditRepo,
+		authService:  authService,
+		auditService: auditService,
+		mailer:       mailer,
+		coolingOff:   coolingOff,
+	}
+}
+
+func (s *accountService) Export(userID string) (*AccountExport, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	export := &AccountExport{
+		ExportedAt: time.Now(),
+		Profile:    user,
+	}
+	if export.Identities, err = s.identityRepo.FindByUserID(userID); err != nil {
+		return nil, err
+	}
+	if export.TwoFactor, err = s.mfaRepo.FindTOTP(userID); err != nil {
+		return nil, err
+	}
+	if export.Sessions, err = s.sessionRepo.FindByUserID(userID); err != nil {
+		return nil, err
+	}
+	if export.PersonalAccessTokens, err = s.patRepo.FindByUserID(userID); err != nil {
+		return nil, err
+	}
+	if export.APIKeys, err = s.apiKeyRepo.FindByUserID(userID); err != nil {
+		return nil, err
+	}
+	if export.OAuthClients, err = s.clientRepo.FindByOwnerID(userID); err != nil {
+		return nil, err
+	}
+	if export.OAuthConsents, err = s.consentRepo.FindByUserID(userID); err != nil {
+		return nil, err
+	}
+	if export.Products, err = s.productRepo.FindByOwnerID(userID); err != nil {
+		return nil, err
+	}
+	if export.AuditEvents, err = s.auditRepo.FindByUserID(userID); err != nil {
+		return nil, err
+	}
+
+	err = s.auditService.Record(&models.AuditEvent{
+		Type:   models.AuditDataExported,
+		UserID: &userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+func (s *accountService) ScheduleDeletion(userID string) (time.Time, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if user == nil {
+		return time.Time{}, ErrUserNotFound
+	}
+	if user.DeletionScheduledAt != nil {
+		return *user.DeletionScheduledAt, nil
+	}
+
+	deleteAt := time.Now().Add(s.coolingOff)
+	if err := s.userRepo.ScheduleDeletion(userID, &deleteAt); err != nil {
+		return time.Time{}, err
+	}
+
+	err = s.auditService.Record(&models.AuditEvent{
+		Type:    models.AuditDeletionScheduled,
+		UserID:  &userID,
+		Details: map[string]string{"delete_at": deleteAt.UTC().Format(time.RFC3339)},
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// The deletion is scheduled either way; the user can still see and
+	// cancel it from their profile
+	err = s.mailer.Send(&Message{
+		To:      user.Email,
+		Subject: "Your account is scheduled for deletion",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYour account and the data held about you will be deleted on %s.\n\nIf you change your mind, log in and cancel the deletion before then. If you did not ask for this, log in, cancel the deletion and change your password.\n",
+			user.Name, deleteAt.UTC().Format("2 January 2006 at 15:04 MST"),
+		),
+	})
+	if err != nil {
+		log.Println("Error sending account deletion email:", err)
+	}
+
+	return deleteAt, nil
+}
+
+func (s *accountService) CancelDeletion(userID string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.DeletionScheduledAt == nil {
+		return ErrDeletionNotScheduled
+	}
+
+	if err := s.userRepo.ScheduleDeletion(userID, nil); err != nil {
+		return err
+	}
+
+	return s.auditService.Record(&models.AuditEvent{
+		Type:   models.AuditDeletionCancelled,
+		UserID: &userID,
+	})
+}
+
+func (s *accountService) PurgeDue() error {
+	users, err := s.userRepo.FindDueForDeletion(time.Now())
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, user := range users {
+		if err := s.purge(user.ID); err != nil {
+			log.Printf("Error purging account %s: %v", user.ID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d accounts could not be purged", failed, len(users))
+	}
+	return nil
+}
+
+// purge deletes the user last, so that an account is found again by the
+// next run until every step has succeeded. Each step can be repeated.
+func (s *accountService) purge(userID string) error {
+	// Revocations are kept after the user row is gone
+	if err := s.authService.RevokeAllTokens(userID); err != nil {
+		return err
+	}
+	if err := s.auditRepo.AnonymizeUser(userID); err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(userID); err != nil {
+		return err
+	}
+
+	// Unlike an admin deletion, no email address is kept
+	return s.auditService.Record(&models.AuditEvent{
+		Type:    models.AuditUserDeleted,
+		UserID:  &userID,
+		Details: map[string]string{"reason": "requested by user"},
+	})
+}
+
+func (s *accountService) StartPurge(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := s.PurgeDue(); err != nil {
+				log.Println("Error purging deleted accounts:", err)
+			}
+		}
+	}()
+}
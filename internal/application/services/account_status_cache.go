@@ -0,0 +1,90 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+// accountStatusTTL bounds how long a user deleted or disabled through another
+// instance keeps passing token validation. Accounts changed through this
+// instance are forgotten when their tokens are revoked.
+const accountStatusTTL = 30 * time.Second
+
+// accountStatus is what validating a token needs to know about its user.
+type accountStatus struct {
+	exists   bool
+	disabled bool
+	role     models.Role
+	until    time.Time
+}
+
+// accountStatusCache keeps account lookups in memory so that validating a
+// token does not query the users table on every request.
+type accountStatusCache struct {
+	userRepo repositories.UserRepository
+	ttl      time.Duration
+
+	mu       sync.RWMutex
+	statuses map[string]accountStatus
+}
+
+func newAccountStatusCache(userRepo repositories.UserRepository, ttl time.Duration) *accountStatusCache {
+	c := &accountStatusCache{
+		userRepo: userRepo,
+		ttl:      ttl,
+		statuses: make(map[string]accountStatus),
+	}
+	go c.evict()
+	return c
+}
+
+func (c *accountStatusCache) get(userID string) (accountStatus, error) {
+	c.mu.RLock()
+	status, ok := c.statuses[userID]
+	c.mu.RUnlock()
+	if ok && time.Now().Before(status.until) {
+		return status, nil
+	}
+
+	user, err := c.userRepo.FindByID(userID)
+	if err != nil {
+		return accountStatus{}, err
+	}
+
+	status = accountStatus{until: time.Now().Add(c.ttl)}
+	if user != nil {
+		status.exists = true
+		status.disabled = user.DisabledAt != nil
+		status.role = user.Role
+	}
+
+	c.mu.Lock()
+	c.statuses[userID] = status
+	c.mu.Unlock()
+	return status, nil
+}
+
+func (c *accountStatusCache) forget(userID string) {
+	c.mu.Lock()
+	delete(c.statuses, userID)
+	c.mu.Unlock()
+}
+
+func (c *accountStatusCache) evict() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		c.mu.Lock()
+		for userID, status := range c.statuses {
+			if now.After(status.until) {
+				delete(c.statuses, userID)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+
+	impersonationTokenTTL = 30 * time.Minute
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	// ErrCannotManageSelf keeps admins from locking themselves out, which
+	// could leave no admin able to undo it.
+	ErrCannotManageSelf = errors.New("admins cannot disable, delete or change the role of their own account")
+	// ErrCannotImpersonate keeps impersonation from being used to act with
+	// another admin's privileges or to get around a disabled account.
+	ErrCannotImpersonate = errors.New("only other enabled non-admin users can be impersonated")
+)
+
+// UserQuery selects a page of users. Page numbers start at 1.
+type UserQuery struct {
+	Search   string      `form:"search"`
+	Role     models.Role `form:"role"`
+	Disabled *bool       `form:"disabled"`
+	Page     int         `form:"page"`
+	PageSize int         `form:"page_size"`
+}
+
+type UserPage struct {
+	Users    []models.User `json:"users"`
+	Total    int           `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
+// ImpersonationToken is an access token for acting as another user. It has
+// no refresh token; the admin impersonates again once it expires.
+type ImpersonationToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	UserID      string `json:"user_id"`
+}
+
+// AdminService manages other users' accounts. Every change is recorded as an
+// audit event naming the admin who made it.
+type AdminService interface {
+	ListUsers(query UserQuery) (*UserPage, error)
+	GetUser(id string) (*models.User, error)
+	// DisableUser blocks the user from logging in and revokes every token
+	// they hold; their API keys stop working until they are enabled again.
+	DisableUser(actorID, id string) error
+	EnableUser(actorID, id string) error
+	// ForcePasswordReset invalidates the user's password and sessions and
+	// emails them a reset link.
+	ForcePasswordReset(actorID, id string) error
+	UpdateRole(actorID, id string, role models.Role) error
+	DeleteUser(actorID, id string) error
+	// Impersonate issues a token for acting as the user. The token's act
+	// claim names the admin, and the reason is kept in the audit log.
+	Impersonate(actorID, id, reason string) (*ImpersonationToken, error)
+}
+
+type adminService struct {
+	userRepo     repositories.UserRepository
+	authService  AuthService
+	resetService PasswordResetService
+	auditService AuditService
+}
+
+func NewAdminService(userRepo repositories.UserRepository, authService AuthService, resetService PasswordResetService, auditService AuditService) AdminService {
+	return &adminService{
+		userRepo:     userRepo,
+		authService:  authService,
+		resetService: resetService,
+		auditService: auditService,
+	}
+}
+
+func (s *adminService) ListUsers(query UserQuery) (*UserPage, error) {
+	if query.Role != "" && !query.Role.Valid() {
+		return nil, errors.New("invalid role")
+	}
+
+	page, pageSize := query.Page, query.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultUserPageSize
+	}
+	if pageSize > maxUserPageSize {
+		pageSize = maxUserPageSize
+	}
+
+	users, total, err := s.userRepo.List(repositories.UserFilter{
+		Search:   query.Search,
+		Role:     query.Role,
+		Disabled: query.Disabled,
+		Limit:    pageSize,
+		Offset:   (page - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserPage{
+		Users:    users,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func (s *adminService) GetUser(id string) (*models.User, error) {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (s *adminService) DisableUser(actorID, id string) error {
+	if actorID == id {
+		return ErrCannotManageSelf
+	}
+	if _, err := s.GetUser(id); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.SetDisabled(id, true); err != nil {
+		return err
+	}
+	if err := s.authService.RevokeAllTokens(id); err != nil {
+		return err
+	}
+
+	return s.record(models.AuditUserDisabled, actorID, id, nil)
+}
+
+func (s *adminService) EnableUser(actorID, id string) error {
+	if _, err := s.GetUser(id); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.SetDisabled(id, false); err != nil {
+		return err
+	}
+
+	return s.record(models.AuditUserEnabled, actorID, id, nil)
+}
+
+func (s *adminService) ForcePasswordReset(actorID, id string) error {
+	if _, err := s.GetUser(id); err != nil {
+		return err
+	}
+
+	if err := s.resetService.ForceReset(id); err != nil {
+		return err
+	}
+
+	return s.record(models.AuditPasswordResetForced, actorID, id, nil)
+}
+
+func (s *adminService) UpdateRole(actorID, id string, role models.Role) error {
+	if !role.Valid() {
+		return errors.New("invalid role")
+	}
+	if actorID == id {
+		return ErrCannotManageSelf
+	}
+
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdateRole(id, role); err != nil {
+		return err
+	}
+	// Tokens carry the old role and its scopes
+	if err := s.authService.RevokeAllTokens(id); err != nil {
+		return err
+	}
+
+	return s.record(models.AuditUserRoleChanged, actorID, id, map[string]string{
+		"from": string(user.Role),
+		"to":   string(role),
+	})
+}
+
+func (s *adminService) DeleteUser(actorID, id string) error {
+	if actorID == id {
+		return ErrCannotManageSelf
+	}
+
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+
+	// Revocations are kept after the user row is gone
+	if err := s.authService.RevokeAllTokens(id); err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(id); err != nil {
+		return err
+	}
+
+	// The user row is gone, so keep the address for the audit trail
+	return s.record(models.AuditUserDeleted, actorID, id, map[string]string{
+		"email": user.Email,
+	})
+}
+
+func (s *adminService) Impersonate(actorID, id, reason string) (*ImpersonationToken, error) {
+	if actorID == id {
+		return nil, ErrCannotImpersonate
+	}
+
+	user, err := s.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == models.RoleAdmin || user.DisabledAt != nil {
+		return nil, ErrCannotImpersonate
+	}
+
+	// Recorded first, so that no token exists without an audit trail
+	err = s.record(models.AuditImpersonationStarted, actorID, id, map[string]string{
+		"reason": reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.authService.GenerateToken(id, TokenOptions{
+		TTL:     impersonationTokenTTL,
+		ActorID: actorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImpersonationToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(impersonationTokenTTL.Seconds()),
+		UserID:      id,
+	}, nil
+}
+
+func (s *adminService) record(eventType, actorID, userID string, details map[string]string) error {
+	return s.auditService.Record(&models.AuditEvent{
+		Type:    eventType,
+		UserID:  &userID,
+		ActorID: &actorID,
+		Details: details,
+	})
+}
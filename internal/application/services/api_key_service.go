@@ -0,0 +1,159 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const (
+	apiKeyPrefix      = "wsk_"
+	apiKeyPrefixBytes = 6
+	apiKeySecretBytes = 32
+)
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+type APIKeyService interface {
+	// Create issues a new key for the user. The key itself is returned only
+	// once; afterwards it is identified by its prefix.
+	Create(userID, name string, scopes []models.Permission, ttl time.Duration) (string, *models.APIKey, error)
+	List(userID string) ([]models.APIKey, error)
+	Revoke(userID, id string) error
+	// Authenticate resolves a presented key to the same claims an access
+	// token for its owner would carry, limited to the key's scopes.
+	Authenticate(key string) (*AccessClaims, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo repositories.APIKeyRepository
+	userRepo   repositories.UserRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepository, userRepo repositories.UserRepository) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (s *apiKeyService) Create(userID, name string, scopes []models.Permission, ttl time.Duration) (string, *models.APIKey, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if user == nil {
+		return "", nil, errors.New("user not found")
+	}
+
+	if len(scopes) == 0 {
+		return "", nil, errors.New("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			return "", nil, errors.New("unknown scope " + string(scope))
+		}
+	}
+	if !models.ScopeIncludes(user.Role.Permissions(), scopes) {
+		return "", nil, ErrScopeNotAllowed
+	}
+
+	prefixBytes := make([]byte, apiKeyPrefixBytes)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", nil, err
+	}
+	prefix := apiKeyPrefix + hex.EncodeToString(prefixBytes)
+
+	secret, err := generateOpaqueToken(apiKeySecretBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	key := prefix + "_" + secret
+
+	now := time.Now()
+	apiKey := &models.APIKey{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashToken(key),
+		Scopes:    scopes,
+		CreatedAt: now,
+	}
+	if ttl > 0 {
+		expiresAt := now.Add(ttl)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	if err := s.apiKeyRepo.Create(apiKey); err != nil {
+		return "", nil, err
+	}
+
+	return key, apiKey, nil
+}
+
+func (s *apiKeyService) List(userID string) ([]models.APIKey, error) {
+	return s.apiKeyRepo.FindByUserID(userID)
+}
+
+func (s *apiKeyService) Revoke(userID, id string) error {
+	apiKey, err := s.apiKeyRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if apiKey == nil || apiKey.UserID != userID || apiKey.RevokedAt != nil {
+		return ErrAPIKeyNotFound
+	}
+
+	return s.apiKeyRepo.Revoke(id)
+}
+
+func (s *apiKeyService) Authenticate(key string) (*AccessClaims, error) {
+	apiKey, err := s.apiKeyRepo.FindByHash(hashToken(key))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil || apiKey.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		return nil, errors.New("api key expired")
+	}
+
+	user, err := s.userRepo.FindByID(apiKey.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(apiKey.ID); err != nil {
+		return nil, err
+	}
+
+	// The owner's role may have been narrowed since the key was created
+	claims := &AccessClaims{
+		UserID:        user.ID,
+		Role:          user.Role,
+		Scopes:        narrowScopes(apiKey.Scopes, user.Role),
+		APIKeyID:      apiKey.ID,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		IssuedAt:      apiKey.CreatedAt,
+	}
+	if apiKey.ExpiresAt != nil {
+		claims.ExpiresAt = *apiKey.ExpiresAt
+	}
+	return claims, nil
+}
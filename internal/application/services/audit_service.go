@@ -0,0 +1,28 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+type AuditService interface {
+	// Record stores the event, filling in its ID and timestamp.
+	Record(event *models.AuditEvent) error
+}
+
+type auditService struct {
+	auditRepo repositories.AuditEventRepository
+}
+
+func NewAuditService(auditRepo repositories.AuditEventRepository) AuditService {
+	return &auditService{auditRepo: auditRepo}
+}
+
+func (s *auditService) Record(event *models.AuditEvent) error {
+	event.ID = uuid.New().String()
+	event.CreatedAt = time.Now()
+	return s.auditRepo.Create(event)
+}
@@ -5,60 +5,861 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
 )
 
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+	mfaTokenTTL     = 5 * time.Minute
+	magicLinkTTL    = 15 * time.Minute
+
+	// ReauthenticationTTL is how long a re-authenticated token lasts, and so
+	// how long sensitive operations stay allowed after re-entering
+	// credentials.
+	ReauthenticationTTL = 5 * time.Minute
+
+	emailVerificationTokenTTL = 24 * time.Hour
+
+	refreshTokenBytes = 32
+
+	// The token_use claim keeps MFA challenge tokens from being accepted as
+	// access tokens. Tokens issued before the claim existed are access tokens.
+	tokenUseAccess            = "access"
+	tokenUseMFA               = "mfa"
+	tokenUseEmailVerification = "email_verification"
+	tokenUseMagicLink         = "magic_link"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrTokenRevoked        = errors.New("token has been revoked")
+	ErrScopeNotAllowed     = errors.New("requested scope exceeds the user's permissions")
+	ErrInvalidMFAToken     = errors.New("invalid or expired mfa token")
+	ErrInvalidVerifyToken  = errors.New("invalid or expired verification token")
+	ErrInvalidMagicLink    = errors.New("invalid or expired login link")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrAccountDisabled     = errors.New("account is disabled")
+	ErrAccountDeleted      = errors.New("account no longer exists")
+	ErrNoLoginSession      = errors.New("token does not belong to a login session")
+
+	errInvalidSingleUseToken = errors.New("invalid single-use token")
+)
+
+// TokenOptions customise a generated access token. The zero value issues a
+// regular short-lived token carrying every permission of the user's role.
+type TokenOptions struct {
+	// TokenID becomes the jti claim; a random ID is used when empty.
+	TokenID string
+	// Scopes restricts the token to a subset of the role's permissions.
+	Scopes []models.Permission
+	// TTL overrides the default access token lifetime.
+	TTL time.Duration
+	// SessionID becomes the sid claim of tokens issued to a login session.
+	SessionID string
+	// ClientID names the OAuth client a delegated token was issued to.
+	ClientID string
+	// ActorID names the admin an impersonation token was issued to; it
+	// becomes the act claim of RFC 8693.
+	ActorID string
+	// AuthTime is when the user last entered their credentials, set on
+	// tokens issued by re-authentication. It becomes the auth_time claim.
+	AuthTime time.Time
+}
+
+// ClientInfo describes the device a login or refresh request came from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// AccessClaims are the verified claims of an access token. Requests
+// authenticated with an API key carry the same claims, with APIKeyID set
+// instead of TokenID.
+type AccessClaims struct {
+	TokenID   string
+	SessionID string
+	UserID    string
+	Role      models.Role
+	Scopes    []models.Permission
+	APIKeyID  string
+	// ClientID is set on tokens issued to third-party OAuth clients.
+	ClientID string
+	// ActorID is set on impersonation tokens to the admin acting as UserID.
+	ActorID string
+	// AuthTime is set on tokens issued by re-authentication.
+	AuthTime time.Time
+	// EmailVerified is captured when the token is issued, so a user who
+	// verifies their email must refresh to obtain a token that says so.
+	EmailVerified bool
+	IssuedAt      time.Time
+	ExpiresAt     time.Time
+}
+
+func (c *AccessClaims) HasScope(scope models.Permission) bool {
+	return models.ScopeIncludes(c.Scopes, []models.Permission{scope})
+}
+
+// TokenPair is returned on login and on every refresh. The refresh token is
+// opaque and only its SHA-256 hash is stored server side.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// ReauthenticatedToken is a short-lived access token for the same session
+// that allows sensitive operations. It has no refresh token; the client goes
+// back to its regular token once done.
+type ReauthenticatedToken struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int64     `json:"expires_in"`
+	AuthTime    time.Time `json:"auth_time"`
+}
+
 type AuthService interface {
-	GenerateToken(userID string) (string, error)
-	ValidateToken(tokenString string) (string, error)
+	GenerateToken(userID string, opts TokenOptions) (string, error)
+	ValidateToken(tokenString string) (*AccessClaims, error)
+	// IssueTokenPair starts a new session for the user.
+	IssueTokenPair(userID string, client ClientInfo) (*TokenPair, error)
+	RefreshTokens(refreshToken string, client ClientInfo) (*TokenPair, error)
+	RevokeToken(claims *AccessClaims) error
+	RevokeRefreshToken(userID, refreshToken string) error
+	RevokeAllTokens(userID string) error
+	// RevokeClientTokens rejects the access tokens issued so far to the
+	// OAuth client on behalf of the user, or of every user if userID is
+	// empty.
+	RevokeClientTokens(clientID, userID string) error
+	ListSessions(userID string) ([]models.Session, error)
+	// RevokeSession ends one of the user's sessions. Its refresh tokens stop
+	// working immediately and its access tokens are rejected from the next
+	// request on.
+	RevokeSession(userID, sessionID string) error
+	// RevokeOtherSessions ends every session of the user except the one
+	// given.
+	RevokeOtherSessions(userID, keepSessionID string) error
+	// IssueReauthenticatedToken issues a token carrying an auth_time claim
+	// to a user who has just re-entered their credentials. It keeps the
+	// session and scopes of the token the request was made with, which must
+	// belong to a login session.
+	IssueReauthenticatedToken(claims *AccessClaims) (*ReauthenticatedToken, error)
+	// GenerateMFAToken issues a short-lived challenge token proving that the
+	// user's password has been verified but the second factor has not.
+	GenerateMFAToken(userID string) (string, int64, error)
+	// ConsumeMFAToken validates a challenge token and revokes it, so that each
+	// token allows exactly one attempt at the second factor.
+	ConsumeMFAToken(tokenString string) (string, error)
+	// GenerateEmailVerificationToken signs a link token bound to the user's
+	// current email address.
+	GenerateEmailVerificationToken(user *models.User) (string, error)
+	// ParseEmailVerificationToken returns the user ID and email address a
+	// verification token was issued for.
+	ParseEmailVerificationToken(tokenString string) (string, string, error)
+	// GenerateMagicLinkToken signs a short-lived login link token bound to
+	// the user's current email address.
+	GenerateMagicLinkToken(user *models.User) (string, error)
+	// ConsumeMagicLinkToken validates a login link token and revokes it,
+	// returning the user ID and email address it was issued for.
+	ConsumeMagicLinkToken(tokenString string) (string, string, error)
 }
 
 type authService struct {
-	jwtSecret string
+	keys             KeyManager
+	userRepo         repositories.UserRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	denylistRepo     repositories.TokenDenylistRepository
+	sessionRepo      repositories.SessionRepository
+	accounts         *accountStatusCache
 }
 
-func NewAuthService(jwtSecret string) AuthService {
-	return &authService{jwtSecret: jwtSecret}
+func NewAuthService(keys KeyManager, userRepo repositories.UserRepository, refreshTokenRepo repositories.RefreshTokenRepository, denylistRepo repositories.TokenDenylistRepository, sessionRepo repositories.SessionRepository) AuthService {
+	return &authService{
+		keys:             keys,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		denylistRepo:     denylistRepo,
+		sessionRepo:      sessionRepo,
+		accounts:         newAccountStatusCache(userRepo, accountStatusTTL),
+	}
 }
 
-func (s *authService) GenerateToken(userID string) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
+func (s *authService) GenerateToken(userID string, opts TokenOptions) (string, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", errors.New("user not found")
+	}
+	if user.DisabledAt != nil {
+		return "", ErrAccountDisabled
+	}
+
+	scopes := opts.Scopes
+	if scopes == nil {
+		scopes = user.Role.Permissions()
+	} else if !models.ScopeIncludes(user.Role.Permissions(), scopes) {
+		return "", ErrScopeNotAllowed
+	}
+
+	tokenID := opts.TokenID
+	if tokenID == "" {
+		tokenID = uuid.New().String()
+	}
+
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = accessTokenTTL
+	}
+
+	key, err := s.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.New(key.Method)
+	token.Header["kid"] = key.ID
+	now := time.Now()
+	expiresAt := now.Add(ttl)
 	claims := token.Claims.(jwt.MapClaims)
-	claims["user_id"] = userID
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix()
+	claims["jti"] = tokenID
+	if opts.SessionID != "" {
+		claims["sid"] = opts.SessionID
+	}
+	if opts.ClientID != "" {
+		claims["client_id"] = opts.ClientID
+	}
+	if opts.ActorID != "" {
+		claims["act"] = map[string]string{"sub": opts.ActorID}
+	}
+	if !opts.AuthTime.IsZero() {
+		claims["auth_time"] = opts.AuthTime.Unix()
+	}
+	claims["sub"] = user.ID
+	claims["user_id"] = user.ID
+	claims["role"] = string(user.Role)
+	claims["scope"] = models.FormatScope(scopes)
+	claims["token_use"] = tokenUseAccess
+	claims["email_verified"] = user.EmailVerifiedAt != nil
+	claims["iat"] = now.Unix()
+	claims["exp"] = expiresAt.Unix()
 
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	tokenString, err := token.SignedString(key.PrivateKey)
 	if err != nil {
 		return "", err
 	}
 
+	if ttl > accessTokenTTL {
+		if err := s.keys.RetainUntil(key.ID, expiresAt); err != nil {
+			return "", err
+		}
+	}
+
 	return tokenString, nil
 }
 
-func (s *authService) ValidateToken(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(s.jwtSecret), nil
+func (s *authService) ValidateToken(tokenString string) (*AccessClaims, error) {
+	mapClaims, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if use, ok := mapClaims["token_use"]; ok && use != tokenUseAccess {
+		return nil, errors.New("invalid token_use in token")
+	}
+
+	claims, err := parseAccessClaims(mapClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkRevocation(claims); err != nil {
+		return nil, err
+	}
+	if err := s.checkAccount(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (s *authService) IssueReauthenticatedToken(claims *AccessClaims) (*ReauthenticatedToken, error) {
+	// API keys and personal access tokens are not used interactively, and
+	// impersonating admins never know the user's credentials
+	if claims.SessionID == "" || claims.ActorID != "" {
+		return nil, ErrNoLoginSession
+	}
+
+	authTime := time.Now()
+	token, err := s.GenerateToken(claims.UserID, TokenOptions{
+		Scopes:    claims.Scopes,
+		TTL:       ReauthenticationTTL,
+		SessionID: claims.SessionID,
+		AuthTime:  authTime,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReauthenticatedToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(ReauthenticationTTL.Seconds()),
+		AuthTime:    authTime,
+	}, nil
+}
+
+func (s *authService) GenerateMFAToken(userID string) (string, int64, error) {
+	tokenString, err := s.signToken(jwt.MapClaims{
+		"jti":       uuid.New().String(),
+		"user_id":   userID,
+		"token_use": tokenUseMFA,
+	}, mfaTokenTTL)
+	if err != nil {
+		return "", 0, err
+	}
 
+	return tokenString, int64(mfaTokenTTL.Seconds()), nil
+}
+
+func (s *authService) ConsumeMFAToken(tokenString string) (string, error) {
+	claims, err := s.consumeSingleUseToken(tokenString, tokenUseMFA)
+	if errors.Is(err, errInvalidSingleUseToken) {
+		return "", ErrInvalidMFAToken
+	}
+	if err != nil {
+		return "", err
+	}
+
+	userID, _ := claims["user_id"].(string)
+	return userID, nil
+}
+
+func (s *authService) GenerateMagicLinkToken(user *models.User) (string, error) {
+	return s.signToken(jwt.MapClaims{
+		"jti":       uuid.New().String(),
+		"user_id":   user.ID,
+		"email":     user.Email,
+		"token_use": tokenUseMagicLink,
+	}, magicLinkTTL)
+}
+
+func (s *authService) ConsumeMagicLinkToken(tokenString string) (string, string, error) {
+	claims, err := s.consumeSingleUseToken(tokenString, tokenUseMagicLink)
+	if errors.Is(err, errInvalidSingleUseToken) {
+		return "", "", ErrInvalidMagicLink
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	userID, _ := claims["user_id"].(string)
+	email, _ := claims["email"].(string)
+	if email == "" {
+		return "", "", ErrInvalidMagicLink
+	}
+	return userID, email, nil
+}
+
+// consumeSingleUseToken validates a token with the given token_use and adds
+// it to the denylist, so that it is accepted only once. Invalid, expired and
+// already used tokens yield errInvalidSingleUseToken.
+func (s *authService) consumeSingleUseToken(tokenString, use string) (jwt.MapClaims, error) {
+	mapClaims, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, errInvalidSingleUseToken
+	}
+
+	if tokenUse, _ := mapClaims["token_use"].(string); tokenUse != use {
+		return nil, errInvalidSingleUseToken
+	}
+
+	tokenID, _ := mapClaims["jti"].(string)
+	userID, _ := mapClaims["user_id"].(string)
+	iat, _ := mapClaims["iat"].(float64)
+	exp, _ := mapClaims["exp"].(float64)
+	if tokenID == "" || userID == "" {
+		return nil, errInvalidSingleUseToken
+	}
+
+	claims := &AccessClaims{
+		TokenID:   tokenID,
+		UserID:    userID,
+		IssuedAt:  time.Unix(int64(iat), 0),
+		ExpiresAt: time.Unix(int64(exp), 0),
+	}
+	if err := s.checkRevocation(claims); err != nil {
+		if errors.Is(err, ErrTokenRevoked) {
+			return nil, errInvalidSingleUseToken
+		}
+		return nil, err
+	}
+
+	if err := s.RevokeToken(claims); err != nil {
+		return nil, err
+	}
+
+	return mapClaims, nil
+}
+
+func (s *authService) GenerateEmailVerificationToken(user *models.User) (string, error) {
+	return s.signToken(jwt.MapClaims{
+		"jti":       uuid.New().String(),
+		"user_id":   user.ID,
+		"email":     user.Email,
+		"token_use": tokenUseEmailVerification,
+	}, emailVerificationTokenTTL)
+}
+
+func (s *authService) ParseEmailVerificationToken(tokenString string) (string, string, error) {
+	claims, err := s.parseToken(tokenString)
+	if err != nil {
+		return "", "", ErrInvalidVerifyToken
+	}
+
+	if use, _ := claims["token_use"].(string); use != tokenUseEmailVerification {
+		return "", "", ErrInvalidVerifyToken
+	}
+
+	userID, _ := claims["user_id"].(string)
+	email, _ := claims["email"].(string)
+	if userID == "" || email == "" {
+		return "", "", ErrInvalidVerifyToken
+	}
+
+	return userID, email, nil
+}
+
+// signToken signs claims with the active key, setting them to expire after
+// ttl. Keys are only kept for accessTokenTTL after rotation, so for longer
+// TTLs the key is retained until the token expires.
+func (s *authService) signToken(claims jwt.MapClaims, ttl time.Duration) (string, error) {
+	key, err := s.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims["iat"] = now.Unix()
+	claims["exp"] = expiresAt.Unix()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.PrivateKey)
 	if err != nil {
 		return "", err
 	}
 
+	if ttl > accessTokenTTL {
+		if err := s.keys.RetainUntil(key.ID, expiresAt); err != nil {
+			return "", err
+		}
+	}
+
+	return tokenString, nil
+}
+
+// parseToken verifies the token signature and expiry and returns its claims.
+func (s *authService) parseToken(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, s.verificationKey)
+
+	if err != nil {
+		return nil, err
+	}
+
 	if !token.Valid {
-		return "", errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", errors.New("invalid token claims")
+		return nil, errors.New("invalid token claims")
+	}
+
+	return claims, nil
+}
+
+// verificationKey resolves the public key named by the token's kid header and
+// makes sure the token was signed with that key's algorithm.
+func (s *authService) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("missing kid in token header")
+	}
+
+	key, err := s.keys.VerificationKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return key.PublicKey, nil
+}
+
+// checkRevocation rejects tokens that were logged out individually, whose
+// session was ended, or that were issued before the user logged out
+// everywhere. Ended sessions are denylisted by their ID, which cannot clash
+// with a token ID as both are random UUIDs.
+func (s *authService) checkRevocation(claims *AccessClaims) error {
+	for _, id := range []string{claims.TokenID, claims.SessionID} {
+		if id == "" {
+			continue
+		}
+		revoked, err := s.denylistRepo.IsRevoked(id)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return ErrTokenRevoked
+		}
+	}
+
+	// Impersonation tokens end when either the user or the admin acting as
+	// them is signed out everywhere, for example by being disabled
+	for _, userID := range []string{claims.UserID, claims.ActorID} {
+		if userID == "" {
+			continue
+		}
+		before, err := s.denylistRepo.RevokedBefore(userID)
+		if err != nil {
+			return err
+		}
+		if before != nil && !claims.IssuedAt.After(*before) {
+			return ErrTokenRevoked
+		}
+	}
+
+	// Tokens issued to an OAuth client end when the user withdraws their
+	// consent or the client is deleted
+	if claims.ClientID != "" {
+		before, err := s.denylistRepo.ClientRevokedBefore(claims.ClientID, claims.UserID)
+		if err != nil {
+			return err
+		}
+		if before != nil && !claims.IssuedAt.After(*before) {
+			return ErrTokenRevoked
+		}
+	}
+
+	return nil
+}
+
+// checkAccount rejects tokens whose user, or the admin impersonating them,
+// has been deleted or disabled since the token was issued. Revocation covers
+// this too, but only when it was recorded. The token's role and scopes are
+// narrowed to the user's current role, as personal access tokens may
+// outlive it by months.
+func (s *authService) checkAccount(claims *AccessClaims) error {
+	for _, userID := range []string{claims.UserID, claims.ActorID} {
+		if userID == "" {
+			continue
+		}
+		status, err := s.accounts.get(userID)
+		if err != nil {
+			return err
+		}
+		if !status.exists {
+			return ErrAccountDeleted
+		}
+		if status.disabled {
+			return ErrAccountDisabled
+		}
+		if userID == claims.UserID {
+			claims.Role = status.role
+			claims.Scopes = narrowScopes(claims.Scopes, status.role)
+		}
+	}
+	return nil
+}
+
+// narrowScopes drops the scopes the role does not grant. The result is never
+// nil, which TokenOptions would take as every permission of the role.
+func narrowScopes(scopes []models.Permission, role models.Role) []models.Permission {
+	narrowed := []models.Permission{}
+	for _, scope := range scopes {
+		if role.HasPermission(scope) {
+			narrowed = append(narrowed, scope)
+		}
+	}
+	return narrowed
+}
+
+func (s *authService) RevokeToken(claims *AccessClaims) error {
+	return s.denylistRepo.Add(&models.RevokedToken{
+		JTI:       claims.TokenID,
+		UserID:    claims.UserID,
+		ExpiresAt: claims.ExpiresAt,
+		RevokedAt: time.Now(),
+	})
+}
+
+// RevokeRefreshToken ends the login the refresh token belongs to. Tokens that
+// are unknown or owned by another user are ignored.
+func (s *authService) RevokeRefreshToken(userID, refreshToken string) error {
+	token, err := s.refreshTokenRepo.FindByHash(hashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	if token == nil || token.UserID != userID {
+		return nil
+	}
+
+	return s.endSession(userID, token.FamilyID)
+}
+
+func (s *authService) RevokeAllTokens(userID string) error {
+	if err := s.denylistRepo.RevokeAllForUser(userID, time.Now()); err != nil {
+		return err
+	}
+	// Disabling and deleting a user revoke their tokens, so the account is
+	// looked up afresh
+	s.accounts.forget(userID)
+
+	if err := s.refreshTokenRepo.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeAllForUser(userID)
+}
+
+func (s *authService) RevokeClientTokens(clientID, userID string) error {
+	now := time.Now()
+	return s.denylistRepo.RevokeAllForClient(clientID, userID, now, now.Add(oauthAccessTokenTTL))
+}
+
+func (s *authService) ListSessions(userID string) ([]models.Session, error) {
+	return s.sessionRepo.FindActiveByUserID(userID)
+}
+
+func (s *authService) RevokeSession(userID, sessionID string) error {
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	return s.endSession(userID, sessionID)
+}
+
+func (s *authService) RevokeOtherSessions(userID, keepSessionID string) error {
+	sessions, err := s.sessionRepo.FindActiveByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.ID == keepSessionID {
+			continue
+		}
+		if err := s.endSession(userID, session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// endSession revokes the session's refresh token family and denylists its
+// ID for as long as access tokens issued to it may still be valid.
+func (s *authService) endSession(userID, sessionID string) error {
+	if err := s.refreshTokenRepo.RevokeFamily(sessionID); err != nil {
+		return err
+	}
+
+	if err := s.sessionRepo.Revoke(sessionID); err != nil {
+		return err
+	}
+
+	return s.RevokeToken(&AccessClaims{
+		TokenID:   sessionID,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(accessTokenTTL),
+	})
+}
+
+// IssueTokenPair starts a new session, whose ID is also the family ID of its
+// refresh tokens.
+func (s *authService) IssueTokenPair(userID string, client ClientInfo) (*TokenPair, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
+	}
+
+	sessionID := uuid.New().String()
+	now := time.Now()
+	err = s.sessionRepo.Create(&models.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(refreshTokenTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokenPair(userID, sessionID, uuid.New().String())
+}
+
+// RefreshTokens rotates the presented refresh token. A token that has already
+// been rotated or revoked is treated as stolen: its whole family is revoked so
+// that neither the attacker nor the legitimate client can keep using it.
+func (s *authService) RefreshTokens(refreshToken string, client ClientInfo) (*TokenPair, error) {
+	current, err := s.refreshTokenRepo.FindByHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if current.RevokedAt != nil {
+		if err := s.endSession(current.UserID, current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, ErrRefreshTokenExpired
+	}
+
+	nextID := uuid.New().String()
+	revoked, err := s.refreshTokenRepo.Revoke(current.ID, nextID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		// Another request rotated this token between our read and write.
+		if err := s.endSession(current.UserID, current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	err = s.sessionRepo.Touch(current.FamilyID, client.IPAddress, time.Now().Add(refreshTokenTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokenPair(current.UserID, current.FamilyID, nextID)
+}
+
+func (s *authService) issueTokenPair(userID, familyID, refreshTokenID string) (*TokenPair, error) {
+	accessToken, err := s.GenerateToken(userID, TokenOptions{SessionID: familyID})
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := generateOpaqueToken(refreshTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.refreshTokenRepo.Create(&models.RefreshToken{
+		ID:        refreshTokenID,
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(refreshTokenTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(accessTokenTTL.Seconds()),
+	}, nil
+}
+
+func parseAccessClaims(claims jwt.MapClaims) (*AccessClaims, error) {
+	tokenID, ok := claims["jti"].(string)
+	if !ok || tokenID == "" {
+		return nil, errors.New("invalid jti in token")
 	}
 
 	userID, ok := claims["user_id"].(string)
 	if !ok {
-		return "", errors.New("invalid user_id in token")
+		return nil, errors.New("invalid user_id in token")
 	}
 
-	return userID, nil
+	role, ok := claims["role"].(string)
+	if !ok || !models.Role(role).Valid() {
+		return nil, errors.New("invalid role in token")
+	}
+
+	scope, ok := claims["scope"].(string)
+	if !ok {
+		return nil, errors.New("invalid scope in token")
+	}
+
+	iat, ok := claims["iat"].(float64)
+	if !ok {
+		return nil, errors.New("invalid iat in token")
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errors.New("invalid exp in token")
+	}
+
+	// Personal access tokens do not belong to a session
+	sessionID, _ := claims["sid"].(string)
+	clientID, _ := claims["client_id"].(string)
+
+	var actorID string
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		if actorID, ok = act["sub"].(string); !ok || actorID == "" {
+			return nil, errors.New("invalid act in token")
+		}
+	}
+
+	var authTime time.Time
+	if value, ok := claims["auth_time"].(float64); ok {
+		authTime = time.Unix(int64(value), 0)
+	}
+
+	// Tokens issued before the claim existed belong to users whose email
+	// was marked verified when the column was added
+	emailVerified, ok := claims["email_verified"].(bool)
+	if !ok {
+		emailVerified = true
+	}
+
+	return &AccessClaims{
+		TokenID:       tokenID,
+		SessionID:     sessionID,
+		ClientID:      clientID,
+		ActorID:       actorID,
+		AuthTime:      authTime,
+		UserID:        userID,
+		Role:          models.Role(role),
+		Scopes:        models.ParseScope(scope),
+		EmailVerified: emailVerified,
+		IssuedAt:      time.Unix(int64(iat), 0),
+		ExpiresAt:     time.Unix(int64(exp), 0),
+	}, nil
 }
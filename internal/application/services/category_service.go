@@ -0,0 +1,256 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+var (
+	ErrCategoryNotFound  = errors.New("category not found")
+	ErrCategoryNameTaken = errors.New("a category with this name already exists at this level")
+	ErrCategoryInUse     = errors.New("category has subcategories or products")
+	ErrInvalidCategory   = errors.New("invalid category")
+)
+
+// CategoryService manages the tree of product categories.
+type CategoryService interface {
+	CreateCategory(category *models.Category) error
+	// GetCategory returns the category with its breadcrumbs, or nil if it
+	// does not exist.
+	GetCategory(id uint) (*models.Category, error)
+	// GetTree returns the top-level categories with their descendants.
+	GetTree() ([]models.CategoryTreeNode, error)
+	// UpdateCategory renames the category and, if its parent changed, moves
+	// it along with its descendants.
+	UpdateCategory(category *models.Category) error
+	// DeleteCategory deletes a category that has no subcategories or
+	// products.
+	DeleteCategory(id uint) error
+}
+
+type categoryService struct {
+	categoryRepo repositories.CategoryRepository
+}
+
+func NewCategoryService(categoryRepo repositories.CategoryRepository) CategoryService {
+	return &categoryService{categoryRepo: categoryRepo}
+}
+
+func (s *categoryService) CreateCategory(category *models.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return fmt.Errorf("%w: name is empty", ErrInvalidCategory)
+	}
+	if category.ParentID != nil {
+		parent, err := s.categoryRepo.FindByID(*category.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return fmt.Errorf("%w: parent category %d does not exist", ErrInvalidCategory, *category.ParentID)
+		}
+	}
+	if err := s.checkName(category.ParentID, category.Name, 0); err != nil {
+		return err
+	}
+
+	if err := s.categoryRepo.Create(category); err != nil {
+		return categoryConflict(err)
+	}
+	return s.attachBreadcrumbs(category)
+}
+
+func (s *categoryService) GetCategory(id uint) (*models.Category, error) {
+	category, err := s.categoryRepo.FindByID(id)
+	if err != nil || category == nil {
+		return nil, err
+	}
+	if err := s.attachBreadcrumbs(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *categoryService) GetTree() ([]models.CategoryTreeNode, error) {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Categories come ordered by name, which the grouping keeps; top-level
+	// categories are grouped under 0
+	children := make(map[uint][]models.Category)
+	for _, category := range categories {
+		var parentID uint
+		if category.ParentID != nil {
+			parentID = *category.ParentID
+		}
+		children[parentID] = append(children[parentID], category)
+	}
+
+	var build func(parentID uint) []models.CategoryTreeNode
+	build = func(parentID uint) []models.CategoryTreeNode {
+		nodes := []models.CategoryTreeNode{}
+		for _, category := range children[parentID] {
+			nodes = append(nodes, models.CategoryTreeNode{
+				Category: category,
+				Children: build(category.ID),
+			})
+		}
+		return nodes
+	}
+	return build(0), nil
+}
+
+func (s *categoryService) UpdateCategory(category *models.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return fmt.Errorf("%w: name is empty", ErrInvalidCategory)
+	}
+
+	existing, err := s.categoryRepo.FindByID(category.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrCategoryNotFound
+	}
+	if err := s.checkName(category.ParentID, category.Name, category.ID); err != nil {
+		return err
+	}
+
+	if !sameCategoryID(existing.ParentID, category.ParentID) {
+		if category.ParentID != nil {
+			parent, err := s.categoryRepo.FindByID(*category.ParentID)
+			if err != nil {
+				return err
+			}
+			if parent == nil {
+				return fmt.Errorf("%w: parent category %d does not exist", ErrInvalidCategory, *category.ParentID)
+			}
+			if strings.HasPrefix(parent.Path, existing.Path) {
+				return fmt.Errorf("%w: a category cannot be moved below itself", ErrInvalidCategory)
+			}
+		}
+		if err := s.categoryRepo.Move(category.ID, category.ParentID); err != nil {
+			return categoryConflict(err)
+		}
+	}
+
+	if err := s.categoryRepo.Update(category); err != nil {
+		return categoryConflict(err)
+	}
+
+	updated, err := s.GetCategory(category.ID)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return ErrCategoryNotFound
+	}
+	*category = *updated
+	return nil
+}
+
+func (s *categoryService) DeleteCategory(id uint) error {
+	category, err := s.categoryRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return ErrCategoryNotFound
+	}
+
+	inUse, err := s.categoryRepo.InUse(id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrCategoryInUse
+	}
+
+	return categoryConflict(s.categoryRepo.Delete(id))
+}
+
+func (s *categoryService) checkName(parentID *uint, name string, excludeID uint) error {
+	taken, err := s.categoryRepo.NameTaken(parentID, name, excludeID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrCategoryNameTaken
+	}
+	return nil
+}
+
+// categoryConflict turns a write rejected by the database into the error the
+// checks before it would have returned.
+func categoryConflict(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrCategoryNameConflict):
+		return ErrCategoryNameTaken
+	case errors.Is(err, repositories.ErrCategoryReferenced):
+		return ErrCategoryInUse
+	case errors.Is(err, repositories.ErrCategoryMissing):
+		return fmt.Errorf("%w: parent category does not exist", ErrInvalidCategory)
+	}
+	return err
+}
+
+func (s *categoryService) attachBreadcrumbs(category *models.Category) error {
+	breadcrumbs, err := loadBreadcrumbs(s.categoryRepo, []string{category.Path})
+	if err != nil {
+		return err
+	}
+	category.Breadcrumbs = breadcrumbs[category.Path]
+	return nil
+}
+
+// loadBreadcrumbs returns the breadcrumbs leading to each category path,
+// keyed by path, looking up every category on the way in one query.
+func loadBreadcrumbs(categoryRepo repositories.CategoryRepository, paths []string) (map[string][]models.Breadcrumb, error) {
+	var ids []uint
+	seen := make(map[uint]bool)
+	for _, path := range paths {
+		for _, id := range models.CategoryPathIDs(path) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	breadcrumbs := make(map[string][]models.Breadcrumb, len(paths))
+	if len(ids) == 0 {
+		return breadcrumbs, nil
+	}
+
+	categories, err := categoryRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	for _, path := range paths {
+		crumbs := []models.Breadcrumb{}
+		for _, id := range models.CategoryPathIDs(path) {
+			crumbs = append(crumbs, models.Breadcrumb{ID: id, Name: names[id]})
+		}
+		breadcrumbs[path] = crumbs
+	}
+	return breadcrumbs, nil
+}
+
+func sameCategoryID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+// fakeCategoryRepository holds categories in memory. Only the methods used
+// when updating a category do anything.
+type fakeCategoryRepository struct {
+	repositories.CategoryRepository
+	categories map[uint]*models.Category
+	moved      bool
+	moveErr    error
+}
+
+func newFakeCategoryRepository(categories ...models.Category) *fakeCategoryRepository {
+	repo := &fakeCategoryRepository{categories: make(map[uint]*models.Category)}
+	for i := range categories {
+		repo.categories[categories[i].ID] = &categories[i]
+	}
+	return repo
+}
+
+func (r *fakeCategoryRepository) FindByID(id uint) (*models.Category, error) {
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *category
+	return &found, nil
+}
+
+func (r *fakeCategoryRepository) FindByIDs(ids []uint) ([]models.Category, error) {
+	categories := []models.Category{}
+	for _, id := range ids {
+		if category, ok := r.categories[id]; ok {
+			categories = append(categories, *category)
+		}
+	}
+	return categories, nil
+}
+
+func (r *fakeCategoryRepository) NameTaken(parentID *uint, name string, excludeID uint) (bool, error) {
+	return false, nil
+}
+
+func (r *fakeCategoryRepository) Move(id uint, parentID *uint) error {
+	if r.moveErr != nil {
+		return r.moveErr
+	}
+	r.moved = true
+	r.categories[id].ParentID = parentID
+	return nil
+}
+
+func (r *fakeCategoryRepository) Update(category *models.Category) error {
+	r.categories[category.ID].Name = category.Name
+	return nil
+}
+
+func categoryID(id uint) *uint {
+	return &id
+}
+
+func TestUpdateCategoryMove(t *testing.T) {
+	// 1 ── 4 ── 9
+	// └─ 5
+	// 12
+	categories := []models.Category{
+		{ID: 1, Name: "Furniture", Path: "/1/", Depth: 0},
+		{ID: 4, ParentID: categoryID(1), Name: "Chairs", Path: "/1/4/", Depth: 1},
+		{ID: 9, ParentID: categoryID(4), Name: "Office Chairs", Path: "/1/4/9/", Depth: 2},
+		{ID: 5, ParentID: categoryID(1), Name: "Desks", Path: "/1/5/", Depth: 1},
+		{ID: 12, Name: "Lighting", Path: "/12/", Depth: 0},
+	}
+
+	tests := []struct {
+		name     string
+		id       uint
+		parentID *uint
+		wantErr  error
+	}{
+		{"below itself", 4, categoryID(4), ErrInvalidCategory},
+		{"below its child", 4, categoryID(9), ErrInvalidCategory},
+		{"below its grandchild", 1, categoryID(9), ErrInvalidCategory},
+		{"below a missing parent", 4, categoryID(99), ErrInvalidCategory},
+		{"below a sibling", 4, categoryID(5), nil},
+		{"below a category whose path shares a prefix", 1, categoryID(12), nil},
+		{"to the top level", 9, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeCategoryRepository(categories...)
+			service := NewCategoryService(repo)
+
+			existing := repo.categories[tt.id]
+			err := service.UpdateCategory(&models.Category{
+				ID:       tt.id,
+				ParentID: tt.parentID,
+				Name:     existing.Name,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateCategory() error = %v, want %v", err, tt.wantErr)
+			}
+			if moved := tt.wantErr == nil; repo.moved != moved {
+				t.Errorf("moved = %v, want %v", repo.moved, moved)
+			}
+		})
+	}
+}
+
+func TestUpdateCategoryMapsRejectedMoves(t *testing.T) {
+	tests := []struct {
+		name    string
+		moveErr error
+		wantErr error
+	}{
+		{"name taken by a new sibling", repositories.ErrCategoryNameConflict, ErrCategoryNameTaken},
+		{"parent deleted meanwhile", repositories.ErrCategoryMissing, ErrInvalidCategory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeCategoryRepository(
+				models.Category{ID: 1, Name: "Furniture", Path: "/1/"},
+				models.Category{ID: 2, Name: "Chairs", Path: "/2/"},
+			)
+			repo.moveErr = tt.moveErr
+			service := NewCategoryService(repo)
+
+			err := service.UpdateCategory(&models.Category{ID: 2, ParentID: categoryID(1), Name: "Chairs"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateCategory() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const (
+	emailChangeTTL       = 24 * time.Hour
+	emailChangeRevertTTL = 7 * 24 * time.Hour
+	emailChangeBytes     = 32
+)
+
+var (
+	ErrEmailUnchanged           = errors.New("new email is the current email")
+	ErrInvalidEmailChangeToken  = errors.New("invalid or expired email change link")
+	ErrEmailChangeNotApplicable = errors.New("the email address has changed again since this link was sent")
+)
+
+type EmailChangeService interface {
+	// RequestChange emails a confirmation link to the new address and a
+	// revert link to the current one. The address does not change until the
+	// link is followed, and a new request replaces any pending one.
+	RequestChange(userID, newEmail string) error
+	// Confirm switches the user to the new address.
+	Confirm(token string) error
+	// Revert cancels a pending change or, if it was already confirmed, puts
+	// the old address back and signs the user out everywhere.
+	Revert(token string) error
+}
+
+type emailChangeService struct {
+	changeRepo   repositories.EmailChangeRepository
+	userRepo     repositories.UserRepository
+	resetRepo    repositories.PasswordResetTokenRepository
+	authService  AuthService
+	auditService AuditService
+	mailer       Mailer
+	baseURL      string
+}
+
+func NewEmailChangeService(changeRepo repositories.EmailChangeRepository, userRepo repositories.UserRepository, resetRepo repositories.PasswordResetTokenRepository, authService AuthService, auditService AuditService, mailer Mailer, baseURL string) EmailChangeService {
+	return &emailChangeService{
+		changeRepo:   changeRepo,
+		userRepo:     userRepo,
+		resetRepo:    resetRepo,
+		authService:  authService,
+		auditService: auditService,
+		mailer:       mailer,
+		baseURL:      baseURL,
+	}
+}
+
+func (s *emailChangeService) RequestChange(userID, newEmail string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	if newEmail == user.Email {
+		return ErrEmailUnchanged
+	}
+	if err := s.checkAvailable(newEmail); err != nil {
+		return err
+	}
+
+	if err := s.changeRepo.CancelPendingForUser(userID); err != nil {
+		return err
+	}
+
+	confirmToken, err := generateOpaqueToken(emailChangeBytes)
+	if err != nil {
+		return err
+	}
+	revertToken, err := generateOpaqueToken(emailChangeBytes)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.changeRepo.Create(&models.EmailChange{
+		ID:               uuid.New().String(),
+		UserID:           userID,
+		OldEmail:         user.Email,
+		NewEmail:         newEmail,
+		ConfirmTokenHash: hashToken(confirmToken),
+		RevertTokenHash:  hashToken(revertToken),
+		ExpiresAt:        now.Add(emailChangeTTL),
+		RevertExpiresAt:  now.Add(emailChangeRevertTTL),
+		CreatedAt:        now,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.record(models.AuditEmailChangeRequested, userID, user.Email, newEmail)
+	if err != nil {
+		return err
+	}
+
+	// The old address is told first, so that a hijacked session cannot move
+	// the account away without its owner hearing about it
+	revertLink := s.baseURL + "/api/v1/revert-email-change?token=" + url.QueryEscape(revertToken)
+	err = s.mailer.Send(&Message{
+		To:      user.Email,
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to change the email address of your account to %s. It changes once the new address is confirmed.\n\nIf this was not you, open the link below within %d days to cancel the change, or to undo it if it has already happened, and then change your password.\n\n%s\n",
+			user.Name, newEmail, int(emailChangeRevertTTL.Hours()/24), revertLink,
+		),
+	})
+	if err != nil {
+		return err
+	}
+
+	confirmLink := s.baseURL + "/api/v1/confirm-email-change?token=" + url.QueryEscape(confirmToken)
+	return s.mailer.Send(&Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to start using this address for your account. It expires in %d hours.\n\n%s\n\nIf you did not ask for this, you can ignore this email.\n",
+			user.Name, int(emailChangeTTL.Hours()), confirmLink,
+		),
+	})
+}
+
+func (s *emailChangeService) Confirm(token string) error {
+	hash := hashToken(token)
+	change, err := s.changeRepo.FindPendingByConfirmHash(hash)
+	if err != nil {
+		return err
+	}
+	if change == nil {
+		return ErrInvalidEmailChangeToken
+	}
+
+	// The link is only used up once the address has actually changed, so a
+	// failed check leaves it usable
+	user, err := s.userRepo.FindByID(change.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidEmailChangeToken
+	}
+	if user.Email != change.OldEmail {
+		return ErrEmailChangeNotApplicable
+	}
+	// Someone may have registered the address since the change was requested
+	if err := s.checkAvailable(change.NewEmail); err != nil {
+		return err
+	}
+
+	change, err = s.changeRepo.Confirm(hash)
+	if err != nil {
+		return err
+	}
+	if change == nil {
+		return ErrInvalidEmailChangeToken
+	}
+
+	return s.record(models.AuditEmailChanged, user.ID, change.OldEmail, change.NewEmail)
+}
+
+func (s *emailChangeService) Revert(token string) error {
+	hash := hashToken(token)
+	change, err := s.changeRepo.FindRevertableByHash(hash)
+	if err != nil {
+		return err
+	}
+	if change == nil {
+		return ErrInvalidEmailChangeToken
+	}
+
+	// As with Confirm, the owner's revert link stays usable until the old
+	// address is back in place
+	if change.ConfirmedAt != nil {
+		if err := s.checkRestorable(change); err != nil {
+			return err
+		}
+	}
+
+	change, err = s.changeRepo.Revert(hash)
+	if err != nil {
+		return err
+	}
+	if change == nil {
+		return ErrInvalidEmailChangeToken
+	}
+
+	// The change may have been confirmed after it was looked up, in which case
+	// Revert has restored the address all the same
+	if change.ConfirmedAt != nil {
+		if err := s.secure(change); err != nil {
+			return err
+		}
+	}
+
+	return s.record(models.AuditEmailChangeReverted, change.UserID, change.OldEmail, change.NewEmail)
+}
+
+// checkRestorable reports whether the old address can be put back after a
+// confirmed change.
+func (s *emailChangeService) checkRestorable(change *models.EmailChange) error {
+	user, err := s.userRepo.FindByID(change.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidEmailChangeToken
+	}
+	if user.Email != change.NewEmail {
+		return ErrEmailChangeNotApplicable
+	}
+	return s.checkAvailable(change.OldEmail)
+}
+
+// secure runs after the old address has been restored. Whoever made the
+// change may hold a session and reset links sent to the new address, so both
+// are revoked.
+func (s *emailChangeService) secure(change *models.EmailChange) error {
+	if err := s.resetRepo.InvalidateForUser(change.UserID); err != nil {
+		return err
+	}
+	if err := s.authService.RevokeAllTokens(change.UserID); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(change.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	err = s.mailer.Send(&Message{
+		To:      change.OldEmail,
+		Subject: "Your email address has been restored",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYour account uses %s again and has been signed out everywhere. If you did not change the address yourself, reset your password now.\n",
+			user.Name, change.OldEmail,
+		),
+	})
+	if err != nil {
+		log.Println("Error sending email restored notice:", err)
+	}
+	return nil
+}
+
+func (s *emailChangeService) checkAvailable(email string) error {
+	existingUser, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if existingUser != nil {
+		return ErrEmailTaken
+	}
+	return nil
+}
+
+func (s *emailChangeService) record(eventType, userID, oldEmail, newEmail string) error {
+	return s.auditService.Record(&models.AuditEvent{
+		Type:   eventType,
+		UserID: &userID,
+		Details: map[string]string{
+			"old_email": oldEmail,
+			"new_email": newEmail,
+		},
+	})
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+// verificationResendInterval is the minimum time between two verification
+// emails to the same user.
+const verificationResendInterval = time.Minute
+
+var (
+	ErrEmailAlreadyVerified  = errors.New("email is already verified")
+	ErrVerificationThrottled = errors.New("verification email was sent recently, try again later")
+)
+
+type EmailVerificationService interface {
+	// SendVerification emails a signed verification link to the user.
+	SendVerification(userID string) error
+	// Verify marks the email address a verification token was issued for as
+	// verified. Links for an address the user has since changed are rejected.
+	Verify(token string) error
+}
+
+type emailVerificationService struct {
+	userRepo    repositories.UserRepository
+	authService AuthService
+	mailer      Mailer
+	baseURL     string
+}
+
+func NewEmailVerificationService(userRepo repositories.UserRepository, authService AuthService, mailer Mailer, baseURL string) EmailVerificationService {
+	return &emailVerificationService{
+		userRepo:    userRepo,
+		authService: authService,
+		mailer:      mailer,
+		baseURL:     baseURL,
+	}
+}
+
+func (s *emailVerificationService) SendVerification(userID string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	claimed, err := s.userRepo.ClaimVerificationEmail(userID, verificationResendInterval)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrVerificationThrottled
+	}
+
+	token, err := s.authService.GenerateEmailVerificationToken(user)
+	if err != nil {
+		return err
+	}
+
+	link := s.baseURL + "/api/v1/verify-email?token=" + url.QueryEscape(token)
+	return s.mailer.Send(&Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %d hours.\n\n%s\n",
+			user.Name, int(emailVerificationTokenTTL.Hours()), link,
+		),
+	})
+}
+
+func (s *emailVerificationService) Verify(token string) error {
+	userID, email, err := s.authService.ParseEmailVerificationToken(token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.Email != email {
+		return ErrInvalidVerifyToken
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return s.userRepo.MarkEmailVerified(userID)
+}
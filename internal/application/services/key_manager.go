@@ -0,0 +1,403 @@
+package services
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	rsaKeyBits = 2048
+
+	// keyReloadInterval limits how often an unknown kid triggers a reload,
+	// so that forged headers cannot be used to hammer the database.
+	keyReloadInterval = 10 * time.Second
+
+	// KeyEncryptionKeySize is the length of the AES-256 key protecting the
+	// stored private keys.
+	KeyEncryptionKeySize = 32
+	// sealedKeyPrefix marks an encrypted private key. Keys stored before
+	// encryption was introduced are plain PEM.
+	sealedKeyPrefix = "sealed:"
+)
+
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+// SigningKey is the private half of the key currently used to sign tokens.
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+}
+
+// VerificationKey is the public half of a key that may still verify tokens.
+type VerificationKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	PublicKey crypto.PublicKey
+}
+
+// JSONWebKey is the RFC 7517 representation of a public verification key.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type KeyManager interface {
+	SigningKey() (*SigningKey, error)
+	VerificationKey(kid string) (*VerificationKey, error)
+	JWKS() *JSONWebKeySet
+	// RetainUntil keeps a key verifiable for as long as a long-lived token
+	// signed with it remains valid, even if it is rotated out sooner.
+	RetainUntil(kid string, until time.Time) error
+	// Rotate creates a new signing key. Previous keys keep verifying tokens
+	// until the grace period has passed.
+	Rotate() error
+	// StartRotation checks every interval whether the active key is due for
+	// rotation and reloads keys rotated by other instances.
+	StartRotation(checkInterval time.Duration)
+}
+
+type loadedKey struct {
+	signing      SigningKey
+	verification VerificationKey
+	createdAt    time.Time
+	// unsealed is set on keys stored before encryption was introduced
+	unsealed bool
+}
+
+type keyManager struct {
+	repo             repositories.SigningKeyRepository
+	aead             cipher.AEAD
+	algorithm        string
+	rotationInterval time.Duration
+	gracePeriod      time.Duration
+
+	mu       sync.RWMutex
+	active   *loadedKey
+	keys     map[string]*loadedKey
+	loadedAt time.Time
+}
+
+// NewKeyManager loads the stored keys, encrypting new ones with
+// encryptionKey, which must be KeyEncryptionKeySize bytes.
+func NewKeyManager(repo repositories.SigningKeyRepository, encryptionKey []byte, algorithm string, rotationInterval, gracePeriod time.Duration) (KeyManager, error) {
+	if len(encryptionKey) != KeyEncryptionKeySize {
+		return nil, fmt.Errorf("key encryption key must be %d bytes", KeyEncryptionKeySize)
+	}
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm != AlgorithmRS256 && algorithm != AlgorithmEdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if gracePeriod < accessTokenTTL {
+		return nil, fmt.Errorf("key grace period must be at least %s", accessTokenTTL)
+	}
+
+	m := &keyManager{
+		repo:             repo,
+		aead:             aead,
+		algorithm:        algorithm,
+		rotationInterval: rotationInterval,
+		gracePeriod:      gracePeriod,
+		keys:             make(map[string]*loadedKey),
+	}
+	if err := m.rotateIfDue(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *keyManager) SigningKey() (*SigningKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.active == nil {
+		return nil, errors.New("no active signing key")
+	}
+	signing := m.active.signing
+	return &signing, nil
+}
+
+func (m *keyManager) VerificationKey(kid string) (*VerificationKey, error) {
+	m.mu.RLock()
+	key, ok := m.keys[kid]
+	stale := time.Since(m.loadedAt) > keyReloadInterval
+	m.mu.RUnlock()
+
+	// The key may have been created by another instance since the last load
+	if !ok && stale {
+		if err := m.reload(); err != nil {
+			return nil, err
+		}
+		m.mu.RLock()
+		key, ok = m.keys[kid]
+		m.mu.RUnlock()
+	}
+	if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+
+	verification := key.verification
+	return &verification, nil
+}
+
+func (m *keyManager) JWKS() *JSONWebKeySet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := &JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range m.keys {
+		set.Keys = append(set.Keys, toJSONWebKey(&key.verification))
+	}
+	return set
+}
+
+func (m *keyManager) RetainUntil(kid string, until time.Time) error {
+	return m.repo.RetainUntil(kid, until)
+}
+
+func (m *keyManager) Rotate() error {
+	privateKey, err := generatePrivateKey(m.algorithm)
+	if err != nil {
+		return err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+
+	kid := uuid.New().String()
+	sealed, err := m.seal(kid, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		return err
+	}
+
+	key := &models.SigningKey{
+		ID:         kid,
+		Algorithm:  m.algorithm,
+		PrivateKey: sealed,
+		CreatedAt:  time.Now(),
+	}
+	if err := m.repo.Create(key); err != nil {
+		return err
+	}
+	if err := m.repo.RetireAllExcept(key.ID, time.Now().Add(m.gracePeriod)); err != nil {
+		return err
+	}
+
+	return m.reload()
+}
+
+func (m *keyManager) StartRotation(checkInterval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := m.rotateIfDue(); err != nil {
+				log.Println("Error rotating signing keys:", err)
+			}
+			if err := m.repo.DeleteExpired(); err != nil {
+				log.Println("Error deleting expired signing keys:", err)
+			}
+		}
+	}()
+}
+
+func (m *keyManager) rotateIfDue() error {
+	if err := m.reload(); err != nil {
+		return err
+	}
+
+	// An unencrypted active key is replaced straight away, so that new tokens
+	// are only signed with encrypted keys
+	m.mu.RLock()
+	due := m.active == nil || m.active.unsealed || time.Since(m.active.createdAt) >= m.rotationInterval
+	m.mu.RUnlock()
+
+	if !due {
+		return nil
+	}
+	return m.Rotate()
+}
+
+func (m *keyManager) reload() error {
+	stored, err := m.repo.FindValid()
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*loadedKey, len(stored))
+	var active *loadedKey
+	for i := range stored {
+		key, err := m.parseSigningKey(&stored[i])
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", stored[i].ID, err)
+		}
+		keys[key.signing.ID] = key
+
+		// Keys are ordered newest first
+		if active == nil && stored[i].ExpiresAt == nil {
+			active = key
+		}
+	}
+
+	m.mu.Lock()
+	m.keys = keys
+	m.active = active
+	m.loadedAt = time.Now()
+	m.mu.Unlock()
+	return nil
+}
+
+func generatePrivateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		return rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+}
+
+// seal encrypts a PEM encoded private key for storage. The key ID is
+// authenticated along with it, so that a sealed key cannot be passed off as
+// another.
+func (m *keyManager) seal(kid string, privateKey []byte) (string, error) {
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := m.aead.Seal(nonce, nonce, privateKey, []byte(kid))
+	return sealedKeyPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts a stored private key. Keys stored without encryption are
+// returned as they are, with unsealed set.
+func (m *keyManager) open(stored *models.SigningKey) (privateKey []byte, unsealed bool, err error) {
+	if !strings.HasPrefix(stored.PrivateKey, sealedKeyPrefix) {
+		return []byte(stored.PrivateKey), true, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored.PrivateKey, sealedKeyPrefix))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(sealed) < m.aead.NonceSize() {
+		return nil, false, errors.New("sealed key is too short")
+	}
+	nonce, ciphertext := sealed[:m.aead.NonceSize()], sealed[m.aead.NonceSize():]
+	privateKey, err = m.aead.Open(nil, nonce, ciphertext, []byte(stored.ID))
+	if err != nil {
+		return nil, false, errors.New("cannot decrypt key: wrong key encryption key or corrupted data")
+	}
+	return privateKey, false, nil
+}
+
+func (m *keyManager) parseSigningKey(stored *models.SigningKey) (*loadedKey, error) {
+	privateKey, unsealed, err := m.open(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var method jwt.SigningMethod
+	var signer crypto.Signer
+	switch privateKey := parsed.(type) {
+	case *rsa.PrivateKey:
+		method, signer = jwt.SigningMethodRS256, privateKey
+	case ed25519.PrivateKey:
+		method, signer = jwt.SigningMethodEdDSA, privateKey
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	if method.Alg() != stored.Algorithm {
+		return nil, fmt.Errorf("key type does not match algorithm %s", stored.Algorithm)
+	}
+
+	return &loadedKey{
+		signing: SigningKey{
+			ID:         stored.ID,
+			Method:     method,
+			PrivateKey: signer,
+		},
+		verification: VerificationKey{
+			ID:        stored.ID,
+			Method:    method,
+			PublicKey: signer.Public(),
+		},
+		createdAt: stored.CreatedAt,
+		unsealed:  unsealed,
+	}, nil
+}
+
+func toJSONWebKey(key *VerificationKey) JSONWebKey {
+	jwk := JSONWebKey{
+		Kid: key.ID,
+		Use: "sig",
+		Alg: key.Method.Alg(),
+	}
+
+	switch publicKey := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	}
+	return jwk
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const (
+	// Failed attempts allowed before an account or an IP address is locked.
+	// IP addresses get more room because many users can share one.
+	accountLockThreshold = 5
+	ipLockThreshold      = 20
+
+	// Each further failure after the threshold doubles the lockout.
+	baseLockout = time.Minute
+	maxLockout  = time.Hour
+
+	// Failure counts are forgotten after this long without a failure.
+	failureWindow = 24 * time.Hour
+)
+
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+
+// LoginGuard protects password logins against guessing by counting failures
+// per account and per client IP address.
+type LoginGuard interface {
+	// Check returns how long the client has to wait before it may try to log
+	// in again, or zero when it may try now.
+	Check(email, ip string) (time.Duration, error)
+	// RecordFailure counts a failed attempt and locks the account or IP
+	// address once its threshold has been reached.
+	RecordFailure(email, ip string) error
+	// RecordSuccess clears the account's failures. The IP address is left
+	// alone so that one valid account cannot be used to reset it.
+	RecordSuccess(email string) error
+	// Unlock lifts an account lockout on behalf of an administrator.
+	Unlock(userID, actorID string) error
+}
+
+type loginGuard struct {
+	throttleRepo repositories.LoginThrottleRepository
+	userRepo     repositories.UserRepository
+	auditService AuditService
+}
+
+func NewLoginGuard(throttleRepo repositories.LoginThrottleRepository, userRepo repositories.UserRepository, auditService AuditService) LoginGuard {
+	return &loginGuard{
+		throttleRepo: throttleRepo,
+		userRepo:     userRepo,
+		auditService: auditService,
+	}
+}
+
+func (g *loginGuard) Check(email, ip string) (time.Duration, error) {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range []string{accountThrottleKey(email), ipThrottleKey(ip)} {
+		throttle, err := g.throttleRepo.Find(key)
+		if err != nil {
+			return 0, err
+		}
+		if throttle != nil && throttle.Locked(now) {
+			if remaining := throttle.LockedUntil.Sub(now); remaining > wait {
+				wait = remaining
+			}
+		}
+	}
+	return wait, nil
+}
+
+func (g *loginGuard) RecordFailure(email, ip string) error {
+	if err := g.recordFailure(accountThrottleKey(email), accountLockThreshold, email, ip); err != nil {
+		return err
+	}
+	return g.recordFailure(ipThrottleKey(ip), ipLockThreshold, email, ip)
+}
+
+func (g *loginGuard) recordFailure(key string, threshold int, email, ip string) error {
+	throttle, err := g.throttleRepo.RecordFailure(key, failureWindow)
+	if err != nil {
+		return err
+	}
+	if throttle.Failures < threshold {
+		return nil
+	}
+
+	lockedUntil := time.Now().Add(lockoutDuration(throttle.Failures - threshold))
+	if err := g.throttleRepo.Lock(key, lockedUntil); err != nil {
+		return err
+	}
+
+	event := &models.AuditEvent{
+		Type:      models.AuditLoginLocked,
+		IPAddress: ip,
+		Details: map[string]string{
+			"key":          key,
+			"failures":     strconv.Itoa(throttle.Failures),
+			"locked_until": lockedUntil.UTC().Format(time.RFC3339),
+		},
+	}
+	if user, err := g.userRepo.FindByEmail(email); err == nil && user != nil {
+		event.UserID = &user.ID
+	}
+
+	// A lost audit record must not turn into a successful login attempt
+	if err := g.auditService.Record(event); err != nil {
+		log.Println("Error recording audit event:", err)
+	}
+	return nil
+}
+
+func (g *loginGuard) RecordSuccess(email string) error {
+	return g.throttleRepo.Reset(accountThrottleKey(email))
+}
+
+func (g *loginGuard) Unlock(userID, actorID string) error {
+	user, err := g.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	if err := g.throttleRepo.Reset(accountThrottleKey(user.Email)); err != nil {
+		return err
+	}
+
+	return g.auditService.Record(&models.AuditEvent{
+		Type:    models.AuditLoginUnlocked,
+		UserID:  &user.ID,
+		ActorID: &actorID,
+	})
+}
+
+// lockoutDuration doubles baseLockout for every failure past the threshold.
+func lockoutDuration(excess int) time.Duration {
+	lockout := baseLockout
+	for i := 0; i < excess && lockout < maxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > maxLockout {
+		lockout = maxLockout
+	}
+	return lockout
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+// magicLinkResendInterval is the minimum time between two login links sent
+// to the same user, so that the endpoint cannot be used to flood an inbox.
+const magicLinkResendInterval = time.Minute
+
+type MagicLinkService interface {
+	// RequestLink emails a login link to the user with the given address.
+	// Unknown addresses, throttled requests and mail failures are not
+	// reported, so that callers cannot probe for accounts.
+	RequestLink(email string) error
+	// Login consumes a login link token and returns the user it signs in.
+	Login(token string) (*models.User, error)
+}
+
+type magicLinkService struct {
+	userRepo    repositories.UserRepository
+	authService AuthService
+	mailer      Mailer
+	linkURL     string
+}
+
+// NewMagicLinkService sends links to linkURL with the token added as the
+// token query parameter.
+func NewMagicLinkService(userRepo repositories.UserRepository, authService AuthService, mailer Mailer, linkURL string) MagicLinkService {
+	return &magicLinkService{
+		userRepo:    userRepo,
+		authService: authService,
+		mailer:      mailer,
+		linkURL:     linkURL,
+	}
+}
+
+func (s *magicLinkService) RequestLink(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.DisabledAt != nil {
+		return nil
+	}
+
+	sendUnobserved("login link", func() error {
+		return s.sendLink(user)
+	})
+	return nil
+}
+
+func (s *magicLinkService) sendLink(user *models.User) error {
+	claimed, err := s.userRepo.ClaimMagicLinkEmail(user.ID, magicLinkResendInterval)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	token, err := s.authService.GenerateMagicLinkToken(user)
+	if err != nil {
+		return err
+	}
+
+	link := appendQuery(s.linkURL, url.Values{"token": {token}})
+	return s.mailer.Send(&Message{
+		To:      user.Email,
+		Subject: "Your login link",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to log in. It works once and expires in %d minutes.\n\n%s\n\nIf you did not ask to log in, you can ignore this email.\n",
+			user.Name, int(magicLinkTTL.Minutes()), link,
+		),
+	})
+}
+
+func (s *magicLinkService) Login(token string) (*models.User, error) {
+	userID, email, err := s.authService.ConsumeMagicLinkToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	// Links sent to an address the user has since changed no longer work
+	if user == nil || user.Email != email {
+		return nil, ErrInvalidMagicLink
+	}
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
+	}
+
+	// Following the link proves the user controls the address
+	if user.EmailVerifiedAt == nil {
+		if err := s.userRepo.MarkEmailVerified(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
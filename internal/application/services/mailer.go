@@ -0,0 +1,28 @@
+package services
+
+import "log"
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email. Implementations live in
+// internal/infrastructure/mail.
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// sendUnobserved sends mail for a request that must answer the same whether
+// or not the address belongs to an account. Waiting for the mail server would
+// make known addresses slower to answer, so sending happens after the
+// response and failures, which would also give them away, are only logged.
+func sendUnobserved(what string, send func() error) {
+	go func() {
+		if err := send(); err != nil {
+			log.Println("Error sending "+what+":", err)
+		}
+	}()
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const (
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+var (
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrInvalidMFACode    = errors.New("invalid two-factor authentication code")
+)
+
+// TOTPEnrollment is shown to the user once so they can add the secret to an
+// authenticator app.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type MFAService interface {
+	IsEnabled(userID string) (bool, error)
+	// Enroll starts a new pending TOTP enrollment for the user.
+	Enroll(userID string) (*TOTPEnrollment, error)
+	// Confirm enables the pending enrollment once the user proves they can
+	// generate codes, and returns a fresh set of recovery codes.
+	Confirm(userID, code string) ([]string, error)
+	// Verify accepts either a current TOTP code or an unused recovery code.
+	Verify(userID, code string) error
+	RegenerateRecoveryCodes(userID, code string) ([]string, error)
+	// Disable turns two-factor authentication off after checking a second
+	// factor. Callers make sure the user has recently re-authenticated.
+	Disable(userID, code string) error
+}
+
+type mfaService struct {
+	mfaRepo     repositories.MFARepository
+	userService UserService
+	issuer      string
+}
+
+func NewMFAService(mfaRepo repositories.MFARepository, userService UserService, issuer string) MFAService {
+	return &mfaService{
+		mfaRepo:     mfaRepo,
+		userService: userService,
+		issuer:      issuer,
+	}
+}
+
+func (s *mfaService) IsEnabled(userID string) (bool, error) {
+	credential, err := s.mfaRepo.FindTOTP(userID)
+	if err != nil {
+		return false, err
+	}
+	return credential != nil && credential.Enabled(), nil
+}
+
+func (s *mfaService) Enroll(userID string) (*TOTPEnrollment, error) {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	enabled, err := s.IsEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.mfaRepo.SaveTOTP(&models.TOTPCredential{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    totpURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+func (s *mfaService) Confirm(userID, code string) ([]string, error) {
+	credential, err := s.mfaRepo.FindTOTP(userID)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil {
+		return nil, errors.New("two-factor authentication is not enrolled")
+	}
+	if credential.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if err := s.verifyTOTP(credential, code); err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.EnableTOTP(userID); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(userID)
+}
+
+func (s *mfaService) Verify(userID, code string) error {
+	credential, err := s.mfaRepo.FindTOTP(userID)
+	if err != nil {
+		return err
+	}
+	if credential == nil || !credential.Enabled() {
+		return ErrMFANotEnabled
+	}
+
+	if isTOTPCode(code) {
+		return s.verifyTOTP(credential, code)
+	}
+
+	used, err := s.mfaRepo.UseRecoveryCode(userID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	if err := s.Verify(userID, code); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(userID)
+}
+
+func (s *mfaService) Disable(userID, code string) error {
+	if err := s.Verify(userID, code); err != nil {
+		return err
+	}
+
+	return s.mfaRepo.DeleteTOTP(userID)
+}
+
+func (s *mfaService) verifyTOTP(credential *models.TOTPCredential, code string) error {
+	step, ok := validateTOTP(credential.Secret, code, time.Now())
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	advanced, err := s.mfaRepo.AdvanceTOTPStep(credential.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !advanced {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *mfaService) replaceRecoveryCodes(userID string) ([]string, error) {
+	now := time.Now()
+	plain := make([]string, recoveryCodeCount)
+	codes := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		plain[i] = code
+		codes[i] = models.RecoveryCode{
+			ID:        uuid.New().String(),
+			UserID:    userID,
+			CodeHash:  hashToken(normalizeRecoveryCode(code)),
+			CreatedAt: now,
+		}
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, codes); err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
+
+// generateRecoveryCode returns a code such as "k3v9q-x2m7a".
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(totpEncoding.EncodeToString(b))[:recoveryCodeLength]
+	return code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totpDigits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
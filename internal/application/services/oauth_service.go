@@ -0,0 +1,516 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const (
+	oauthAccessTokenTTL  = time.Hour
+	oauthCodeTTL         = 5 * time.Minute
+	oauthCodeBytes       = 32
+	oauthSecretBytes     = 32
+	oauthMaxRedirectURIs = 10
+)
+
+var (
+	ErrOAuthClientNotFound = errors.New("oauth client not found")
+	ErrInvalidRedirectURI  = errors.New("redirect_uri is not registered for this client")
+	ErrConsentNotFound     = errors.New("consent not found")
+)
+
+// OAuthError is an error response defined by RFC 6749, returned to clients
+// either as a JSON body or as redirect parameters.
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// AuthorizationRequest holds the query parameters of an authorization
+// request. Only the code response type with an S256 PKCE challenge is
+// supported.
+type AuthorizationRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type"`
+	ClientID            string `form:"client_id" json:"client_id"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+}
+
+// AuthorizationResult tells the user agent either to ask the user for consent
+// or where to send the user back to.
+type AuthorizationResult struct {
+	Client          *models.OAuthClient `json:"client,omitempty"`
+	Scopes          []models.Permission `json:"scopes,omitempty"`
+	ConsentRequired bool                `json:"consent_required"`
+	RedirectTo      string              `json:"redirect_to,omitempty"`
+}
+
+// OAuthToken is the RFC 6749 access token response. Refresh tokens are not
+// issued; clients repeat the authorization request, which succeeds without
+// prompting while the user's consent stands.
+type OAuthToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+// TokenIntrospection is the RFC 7662 introspection response.
+type TokenIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+type OAuthService interface {
+	// RegisterClient creates a client owned by the user. Confidential
+	// clients get a secret, returned only once.
+	RegisterClient(ownerID, name string, redirectURIs []string, scopes []models.Permission, confidential bool) (string, *models.OAuthClient, error)
+	ListClients(ownerID string) ([]models.OAuthClient, error)
+	// DeleteClient deletes the client and revokes every token issued to it.
+	DeleteClient(ownerID, clientID string) error
+
+	// Authorize validates an authorization request for the signed-in user.
+	// When the user has already consented to the requested scopes a code is
+	// issued straight away; otherwise consent is required.
+	Authorize(userID string, req *AuthorizationRequest) (*AuthorizationResult, error)
+	// Decide records the user's answer to the consent prompt and returns
+	// where to redirect them.
+	Decide(userID string, req *AuthorizationRequest, approve bool) (*AuthorizationResult, error)
+
+	// AuthenticateClient checks client credentials presented to the token,
+	// introspection and revocation endpoints. Public clients pass an empty
+	// secret.
+	AuthenticateClient(clientID, clientSecret string) (*models.OAuthClient, error)
+	ExchangeCode(client *models.OAuthClient, code, redirectURI, codeVerifier string) (*OAuthToken, error)
+	// ClientCredentials issues a token acting as the client's owner, limited
+	// to the client's scopes.
+	ClientCredentials(client *models.OAuthClient, scope string) (*OAuthToken, error)
+	// Introspect describes a token issued to the client. Tokens of other
+	// clients are reported as inactive.
+	Introspect(client *models.OAuthClient, token string) (*TokenIntrospection, error)
+	RevokeToken(client *models.OAuthClient, token string) error
+
+	ListConsents(userID string) ([]models.OAuthConsent, error)
+	// RevokeConsent withdraws the user's consent and revokes the tokens the
+	// client holds on their behalf.
+	RevokeConsent(userID, clientID string) error
+}
+
+type oauthService struct {
+	clientRepo  repositories.OAuthClientRepository
+	codeRepo    repositories.OAuthAuthorizationCodeRepository
+	consentRepo repositories.OAuthConsentRepository
+	userRepo    repositories.UserRepository
+	authService AuthService
+}
+
+func NewOAuthService(clientRepo repositories.OAuthClientRepository, codeRepo repositories.OAuthAuthorizationCodeRepository, consentRepo repositories.OAuthConsentRepository, userRepo repositories.UserRepository, authService AuthService) OAuthService {
+	return &oauthService{
+		clientRepo:  clientRepo,
+		codeRepo:    codeRepo,
+		consentRepo: consentRepo,
+		userRepo:    userRepo,
+		authService: authService,
+	}
+}
+
+func (s *oauthService) RegisterClient(ownerID, name string, redirectURIs []string, scopes []models.Permission, confidential bool) (string, *models.OAuthClient, error) {
+	if len(redirectURIs) == 0 || len(redirectURIs) > oauthMaxRedirectURIs {
+		return "", nil, errors.New("between 1 and 10 redirect URIs are required")
+	}
+	for _, uri := range redirectURIs {
+		parsed, err := url.Parse(uri)
+		if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+			return "", nil, errors.New("redirect URIs must be absolute and have no fragment")
+		}
+		if parsed.Scheme != "https" && parsed.Hostname() != "localhost" && parsed.Hostname() != "127.0.0.1" {
+			return "", nil, errors.New("redirect URIs must use https outside localhost")
+		}
+	}
+
+	if len(scopes) == 0 {
+		return "", nil, errors.New("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			return "", nil, errors.New("unknown scope " + string(scope))
+		}
+	}
+
+	client := &models.OAuthClient{
+		ID:           uuid.New().String(),
+		OwnerID:      ownerID,
+		Name:         name,
+		RedirectURIs: redirectURIs,
+		Scopes:       scopes,
+		CreatedAt:    time.Now(),
+	}
+
+	var secret string
+	if confidential {
+		var err error
+		if secret, err = generateOpaqueToken(oauthSecretBytes); err != nil {
+			return "", nil, err
+		}
+		client.SecretHash = hashToken(secret)
+	}
+
+	if err := s.clientRepo.Create(client); err != nil {
+		return "", nil, err
+	}
+	return secret, client, nil
+}
+
+func (s *oauthService) ListClients(ownerID string) ([]models.OAuthClient, error) {
+	return s.clientRepo.FindByOwnerID(ownerID)
+}
+
+func (s *oauthService) DeleteClient(ownerID, clientID string) error {
+	client, err := s.clientRepo.FindByID(clientID)
+	if err != nil {
+		return err
+	}
+	if client == nil || client.OwnerID != ownerID {
+		return ErrOAuthClientNotFound
+	}
+
+	if err := s.clientRepo.Delete(clientID); err != nil {
+		return err
+	}
+	return s.authService.RevokeClientTokens(clientID, "")
+}
+
+func (s *oauthService) Authorize(userID string, req *AuthorizationRequest) (*AuthorizationResult, error) {
+	client, redirectURI, scopes, oauthErr, err := s.validateAuthorizationRequest(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if oauthErr != nil {
+		return &AuthorizationResult{RedirectTo: errorRedirect(redirectURI, req.State, oauthErr)}, nil
+	}
+
+	consent, err := s.consentRepo.Find(userID, client.ID)
+	if err != nil {
+		return nil, err
+	}
+	if consent == nil || !models.ScopeIncludes(consent.Scopes, scopes) {
+		return &AuthorizationResult{
+			Client:          client,
+			Scopes:          scopes,
+			ConsentRequired: true,
+		}, nil
+	}
+
+	return s.issueCode(userID, client, redirectURI, scopes, req)
+}
+
+func (s *oauthService) Decide(userID string, req *AuthorizationRequest, approve bool) (*AuthorizationResult, error) {
+	client, redirectURI, scopes, oauthErr, err := s.validateAuthorizationRequest(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if oauthErr == nil && !approve {
+		oauthErr = &OAuthError{Code: "access_denied", Description: "the user denied the request"}
+	}
+	if oauthErr != nil {
+		return &AuthorizationResult{RedirectTo: errorRedirect(redirectURI, req.State, oauthErr)}, nil
+	}
+
+	// Scopes granted earlier stay granted
+	consent, err := s.consentRepo.Find(userID, client.ID)
+	if err != nil {
+		return nil, err
+	}
+	granted := scopes
+	if consent != nil {
+		granted = mergeScopes(consent.Scopes, scopes)
+	}
+
+	err = s.consentRepo.Save(&models.OAuthConsent{
+		UserID:    userID,
+		ClientID:  client.ID,
+		Scopes:    granted,
+		GrantedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueCode(userID, client, redirectURI, scopes, req)
+}
+
+// validateAuthorizationRequest returns an error when the client or redirect
+// URI cannot be trusted, in which case the user must not be redirected.
+// Problems that can be reported to the client are returned as an OAuthError.
+func (s *oauthService) validateAuthorizationRequest(userID string, req *AuthorizationRequest) (*models.OAuthClient, string, []models.Permission, *OAuthError, error) {
+	client, err := s.clientRepo.FindByID(req.ClientID)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+	if client == nil {
+		return nil, "", nil, nil, ErrOAuthClientNotFound
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !client.AllowsRedirectURI(redirectURI) {
+		return nil, "", nil, nil, ErrInvalidRedirectURI
+	}
+
+	if req.ResponseType != "code" {
+		return client, redirectURI, nil, &OAuthError{Code: "unsupported_response_type", Description: "only the code response type is supported"}, nil
+	}
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return client, redirectURI, nil, &OAuthError{Code: "invalid_request", Description: "an S256 code_challenge is required"}, nil
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+	if user == nil {
+		return nil, "", nil, nil, errors.New("user not found")
+	}
+
+	scopes, oauthErr := grantableScopes(client, user.Role, req.Scope)
+	return client, redirectURI, scopes, oauthErr, nil
+}
+
+func (s *oauthService) issueCode(userID string, client *models.OAuthClient, redirectURI string, scopes []models.Permission, req *AuthorizationRequest) (*AuthorizationResult, error) {
+	code, err := generateOpaqueToken(oauthCodeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.codeRepo.Create(&models.OAuthAuthorizationCode{
+		CodeHash:      hashToken(code),
+		ClientID:      client.ID,
+		UserID:        userID,
+		RedirectURI:   redirectURI,
+		Scopes:        scopes,
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     now.Add(oauthCodeTTL),
+		CreatedAt:     now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{"code": {code}}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return &AuthorizationResult{RedirectTo: appendQuery(redirectURI, params)}, nil
+}
+
+func (s *oauthService) AuthenticateClient(clientID, clientSecret string) (*models.OAuthClient, error) {
+	invalid := &OAuthError{Code: "invalid_client", Description: "client authentication failed"}
+
+	client, err := s.clientRepo.FindByID(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, invalid
+	}
+
+	if client.Confidential() {
+		if subtle.ConstantTimeCompare([]byte(hashToken(clientSecret)), []byte(client.SecretHash)) != 1 {
+			return nil, invalid
+		}
+	} else if clientSecret != "" {
+		return nil, invalid
+	}
+
+	return client, nil
+}
+
+func (s *oauthService) ExchangeCode(client *models.OAuthClient, code, redirectURI, codeVerifier string) (*OAuthToken, error) {
+	invalidGrant := &OAuthError{Code: "invalid_grant", Description: "invalid, expired or already used authorization code"}
+
+	grant, err := s.codeRepo.Consume(hashToken(code))
+	if err != nil {
+		return nil, err
+	}
+	if grant == nil || grant.ClientID != client.ID || grant.RedirectURI != redirectURI {
+		return nil, invalidGrant
+	}
+	if !verifyPKCE(codeVerifier, grant.CodeChallenge) {
+		return nil, &OAuthError{Code: "invalid_grant", Description: "code_verifier does not match the code challenge"}
+	}
+
+	return s.issueToken(grant.UserID, client, grant.Scopes)
+}
+
+// verifyPKCE reports whether the verifier matches an S256 code challenge,
+// RFC 7636 section 4.6.
+func verifyPKCE(verifier, challenge string) bool {
+	return verifier != "" && subtle.ConstantTimeCompare([]byte(pkceChallenge(verifier)), []byte(challenge)) == 1
+}
+
+func (s *oauthService) ClientCredentials(client *models.OAuthClient, scope string) (*OAuthToken, error) {
+	if !client.Confidential() {
+		return nil, &OAuthError{Code: "unauthorized_client", Description: "public clients cannot use the client_credentials grant"}
+	}
+
+	owner, err := s.userRepo.FindByID(client.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, &OAuthError{Code: "invalid_client", Description: "client owner no longer exists"}
+	}
+
+	scopes, oauthErr := grantableScopes(client, owner.Role, scope)
+	if oauthErr != nil {
+		return nil, oauthErr
+	}
+
+	return s.issueToken(owner.ID, client, scopes)
+}
+
+func (s *oauthService) issueToken(userID string, client *models.OAuthClient, scopes []models.Permission) (*OAuthToken, error) {
+	token, err := s.authService.GenerateToken(userID, TokenOptions{
+		Scopes:   scopes,
+		TTL:      oauthAccessTokenTTL,
+		ClientID: client.ID,
+	})
+	if errors.Is(err, ErrScopeNotAllowed) || errors.Is(err, ErrAccountDisabled) {
+		// The user's role or status changed since the code was issued
+		return nil, &OAuthError{Code: "invalid_grant", Description: err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuthToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(oauthAccessTokenTTL.Seconds()),
+		Scope:       models.FormatScope(scopes),
+	}, nil
+}
+
+func (s *oauthService) Introspect(client *models.OAuthClient, token string) (*TokenIntrospection, error) {
+	claims, err := s.authService.ValidateToken(token)
+	if err != nil || claims.ClientID != client.ID {
+		return &TokenIntrospection{Active: false}, nil
+	}
+
+	return &TokenIntrospection{
+		Active:    true,
+		Scope:     models.FormatScope(claims.Scopes),
+		ClientID:  claims.ClientID,
+		Subject:   claims.UserID,
+		TokenType: "Bearer",
+		IssuedAt:  claims.IssuedAt.Unix(),
+		ExpiresAt: claims.ExpiresAt.Unix(),
+	}, nil
+}
+
+// RevokeToken follows RFC 7009: tokens that are invalid or belong to another
+// client are ignored rather than reported.
+func (s *oauthService) RevokeToken(client *models.OAuthClient, token string) error {
+	claims, err := s.authService.ValidateToken(token)
+	if err != nil || claims.ClientID != client.ID {
+		return nil
+	}
+
+	return s.authService.RevokeToken(claims)
+}
+
+func (s *oauthService) ListConsents(userID string) ([]models.OAuthConsent, error) {
+	return s.consentRepo.FindByUserID(userID)
+}
+
+func (s *oauthService) RevokeConsent(userID, clientID string) error {
+	deleted, err := s.consentRepo.Delete(userID, clientID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrConsentNotFound
+	}
+	return s.authService.RevokeClientTokens(clientID, userID)
+}
+
+// grantableScopes resolves the requested scope against what the client was
+// registered for, defaulting to all of it, and narrows it to the user's role.
+func grantableScopes(client *models.OAuthClient, role models.Role, scope string) ([]models.Permission, *OAuthError) {
+	requested := models.ParseScope(scope)
+	if len(requested) == 0 {
+		requested = client.Scopes
+	}
+	if !models.ScopeIncludes(client.Scopes, requested) {
+		return nil, &OAuthError{Code: "invalid_scope", Description: "the client is not registered for the requested scope"}
+	}
+
+	granted := make([]models.Permission, 0, len(requested))
+	for _, p := range requested {
+		if role.HasPermission(p) {
+			granted = append(granted, p)
+		}
+	}
+	if len(granted) == 0 {
+		return nil, &OAuthError{Code: "invalid_scope", Description: "the user cannot grant any of the requested scopes"}
+	}
+	return granted, nil
+}
+
+func mergeScopes(a, b []models.Permission) []models.Permission {
+	merged := append([]models.Permission{}, a...)
+	for _, p := range b {
+		if !models.ScopeIncludes(merged, []models.Permission{p}) {
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+func errorRedirect(redirectURI, state string, oauthErr *OAuthError) string {
+	params := url.Values{"error": {oauthErr.Code}}
+	if oauthErr.Description != "" {
+		params.Set("error_description", oauthErr.Description)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	return appendQuery(redirectURI, params)
+}
+
+// appendQuery adds params to a registered redirect URI, keeping any query
+// it already has.
+func appendQuery(uri string, params url.Values) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+
+	query := parsed.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
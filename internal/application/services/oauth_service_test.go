@@ -0,0 +1,40 @@
+package services
+
+import "testing"
+
+// The verifier and challenge of RFC 7636 appendix B.
+const (
+	rfc7636Verifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	rfc7636Challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func TestPKCEChallengeMatchesRFC7636(t *testing.T) {
+	if got := pkceChallenge(rfc7636Verifier); got != rfc7636Challenge {
+		t.Errorf("pkceChallenge() = %s, want %s", got, rfc7636Challenge)
+	}
+}
+
+func TestVerifyPKCE(t *testing.T) {
+	tests := []struct {
+		name      string
+		verifier  string
+		challenge string
+		want      bool
+	}{
+		{"matching verifier", rfc7636Verifier, rfc7636Challenge, true},
+		{"other verifier", "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXK", rfc7636Challenge, false},
+		{"plain method, verifier sent as the challenge", rfc7636Verifier, rfc7636Verifier, false},
+		{"challenge sent as the verifier", rfc7636Challenge, rfc7636Challenge, false},
+		{"empty verifier", "", rfc7636Challenge, false},
+		{"empty verifier and challenge", "", "", false},
+		{"padded challenge", rfc7636Verifier, rfc7636Challenge + "=", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyPKCE(tt.verifier, tt.challenge); got != tt.want {
+				t.Errorf("verifyPKCE() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const (
+	oidcLoginTTL     = 10 * time.Minute
+	oidcRandomBytes  = 32
+	oidcPasswordSize = 32
+)
+
+var (
+	ErrUnknownIdentityProvider = errors.New("unknown identity provider")
+	ErrInvalidOIDCState        = errors.New("invalid or expired login state")
+	ErrIdentityNotLinkable     = errors.New("email must be verified both by the identity provider and on the existing account")
+)
+
+// ExternalIdentity holds the verified claims of an ID token.
+type ExternalIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Nonce         string
+}
+
+// IdentityProvider is an OpenID Connect provider this service relies on.
+// Implementations live in internal/infrastructure/oidc.
+type IdentityProvider interface {
+	// AuthCodeURL returns the provider URL that starts an authorization
+	// code flow with an S256 PKCE challenge.
+	AuthCodeURL(state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems an authorization code and returns the claims of the
+	// verified ID token.
+	Exchange(code, codeVerifier string) (*ExternalIdentity, error)
+}
+
+type OIDCService interface {
+	// Begin starts a login with the named provider and returns the URL the
+	// user has to be sent to.
+	Begin(provider string) (string, error)
+	// Complete finishes a login from the provider's callback and returns the
+	// local user, linking or creating it as needed.
+	Complete(provider, state, code string) (*models.User, error)
+}
+
+type oidcService struct {
+	providers    map[string]IdentityProvider
+	stateRepo    repositories.OIDCLoginStateRepository
+	identityRepo repositories.UserIdentityRepository
+	userRepo     repositories.UserRepository
+	hasher       PasswordHasher
+}
+
+func NewOIDCService(providers map[string]IdentityProvider, stateRepo repositories.OIDCLoginStateRepository, identityRepo repositories.UserIdentityRepository, userRepo repositories.UserRepository, hasher PasswordHasher) OIDCService {
+	return &oidcService{
+		providers:    providers,
+		stateRepo:    stateRepo,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		hasher:       hasher,
+	}
+}
+
+func (s *oidcService) Begin(provider string) (string, error) {
+	idp, ok := s.providers[provider]
+	if !ok {
+		return "", ErrUnknownIdentityProvider
+	}
+
+	state, err := generateOpaqueToken(oidcRandomBytes)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := generateOpaqueToken(oidcRandomBytes)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := generateOpaqueToken(oidcRandomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	err = s.stateRepo.Create(&models.OIDCLoginState{
+		State:        state,
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(oidcLoginTTL),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return idp.AuthCodeURL(state, nonce, pkceChallenge(verifier))
+}
+
+func (s *oidcService) Complete(provider, state, code string) (*models.User, error) {
+	idp, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownIdentityProvider
+	}
+
+	loginState, err := s.stateRepo.Consume(state)
+	if err != nil {
+		return nil, err
+	}
+	if loginState == nil || loginState.Provider != provider {
+		return nil, ErrInvalidOIDCState
+	}
+
+	identity, err := idp.Exchange(code, loginState.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	if identity.Nonce != loginState.Nonce {
+		return nil, errors.New("id token nonce does not match")
+	}
+
+	return s.resolveUser(provider, identity)
+}
+
+// resolveUser finds the user already linked to the identity. Otherwise an
+// existing user with the same email is linked, provided both the provider
+// and the user have verified that address, or a new user is provisioned.
+func (s *oidcService) resolveUser(provider string, identity *ExternalIdentity) (*models.User, error) {
+	linked, err := s.identityRepo.FindByProviderSubject(provider, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linked != nil {
+		user, err := s.userRepo.FindByID(linked.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, errors.New("user not found")
+		}
+		return user, nil
+	}
+
+	if identity.Email == "" {
+		return nil, errors.New("identity provider did not return an email address")
+	}
+
+	user, err := s.userRepo.FindByEmail(identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	// An unverified account may have been registered by someone else, who
+	// would keep signing in with their own password once it is linked
+	if user != nil && (!identity.EmailVerified || user.EmailVerifiedAt == nil) {
+		return nil, ErrIdentityNotLinkable
+	}
+	if user == nil {
+		if user, err = s.provisionUser(identity); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.identityRepo.Create(&models.UserIdentity{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Provider:  provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// provisionUser creates a user for a first-time SSO login. The random
+// password is never revealed; the user can set one with a password reset.
+func (s *oidcService) provisionUser(identity *ExternalIdentity) (*models.User, error) {
+	password, err := generateOpaqueToken(oidcPasswordSize)
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = identity.Email
+	}
+
+	now := time.Now()
+	user := &models.User{
+		ID:           uuid.New().String(),
+		Email:        identity.Email,
+		PasswordHash: passwordHash,
+		Name:         name,
+		Role:         models.RoleViewer,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if identity.EmailVerified {
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2Params keeps hashing fast in tests.
+var testArgon2Params = Argon2Params{
+	Memory:      64,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func newTestHasher(t *testing.T, params Argon2Params) PasswordHasher {
+	t.Helper()
+	hasher, err := NewPasswordHasher(params, 2)
+	if err != nil {
+		t.Fatalf("NewPasswordHasher() error = %v", err)
+	}
+	return hasher
+}
+
+func TestNewPasswordHasherRejectsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(*Argon2Params)
+		maxConcurrent int
+	}{
+		{"no parallelism", func(p *Argon2Params) { p.Parallelism = 0 }, 1},
+		{"no iterations", func(p *Argon2Params) { p.Iterations = 0 }, 1},
+		{"too little memory", func(p *Argon2Params) { p.Memory = 8*uint32(p.Parallelism) - 1 }, 1},
+		{"short salt", func(p *Argon2Params) { p.SaltLength = 8 }, 1},
+		{"short key", func(p *Argon2Params) { p.KeyLength = 8 }, 1},
+		{"no concurrency", func(p *Argon2Params) {}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := testArgon2Params
+			tt.modify(&params)
+			if _, err := NewPasswordHasher(params, tt.maxConcurrent); err == nil {
+				t.Error("NewPasswordHasher() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestPasswordHasherVerify(t *testing.T) {
+	hasher := newTestHasher(t, testArgon2Params)
+
+	argon2Hash, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		hash     string
+		want     bool
+	}{
+		{"argon2id match", "correct horse", argon2Hash, true},
+		{"argon2id mismatch", "battery staple", argon2Hash, false},
+		{"bcrypt match", "correct horse", string(bcryptHash), true},
+		{"bcrypt mismatch", "battery staple", string(bcryptHash), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hasher.Verify(tt.password, tt.hash)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordHasherSaltsEachHash(t *testing.T) {
+	hasher := newTestHasher(t, testArgon2Params)
+
+	first, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	second, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if first == second {
+		t.Error("Hash() returned the same hash twice")
+	}
+}
+
+func TestPasswordHasherRejectsMalformedHashes(t *testing.T) {
+	hasher := newTestHasher(t, testArgon2Params)
+
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{"empty", ""},
+		{"other algorithm", "$argon2i$v=19$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5"},
+		{"other version", "$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5"},
+		{"missing parameters", "$argon2id$v=19$m=64$c2FsdHNhbHRzYWx0c2FsdA$a2V5"},
+		{"zero parallelism", "$argon2id$v=19$m=64,t=1,p=0$c2FsdHNhbHRzYWx0c2FsdA$a2V5"},
+		{"bad salt", "$argon2id$v=19$m=64,t=1,p=1$!!!$a2V5"},
+		{"empty key", "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$"},
+		{"plain text", "correct horse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := hasher.Verify("correct horse", tt.hash)
+			if !errors.Is(err, ErrUnsupportedPasswordHash) {
+				t.Errorf("Verify() error = %v, want ErrUnsupportedPasswordHash", err)
+			}
+			if ok {
+				t.Error("Verify() = true, want false")
+			}
+		})
+	}
+}
+
+func TestPasswordHasherNeedsRehash(t *testing.T) {
+	configured := Argon2Params{
+		Memory:      128,
+		Iterations:  2,
+		Parallelism: 2,
+		SaltLength:  24,
+		KeyLength:   32,
+	}
+	hasher := newTestHasher(t, configured)
+
+	hashWith := func(modify func(*Argon2Params)) string {
+		params := configured
+		modify(&params)
+		hash, err := newTestHasher(t, params).Hash("correct horse")
+		if err != nil {
+			t.Fatalf("Hash() error = %v", err)
+		}
+		return hash
+	}
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{"configured parameters", hashWith(func(p *Argon2Params) {}), false},
+		{"stronger parameters", hashWith(func(p *Argon2Params) { p.Memory, p.Iterations = 256, 3 }), false},
+		{"less memory", hashWith(func(p *Argon2Params) { p.Memory = 64 }), true},
+		{"fewer iterations", hashWith(func(p *Argon2Params) { p.Iterations = 1 }), true},
+		{"less parallelism", hashWith(func(p *Argon2Params) { p.Parallelism = 1 }), true},
+		{"shorter salt", hashWith(func(p *Argon2Params) { p.SaltLength = 16 }), true},
+		{"shorter key", hashWith(func(p *Argon2Params) { p.KeyLength = 16 }), true},
+		{"bcrypt", string(bcryptHash), true},
+		{"malformed", "not a hash", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasher.NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("NeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+)
+
+// Password policy violation codes
+const (
+	PasswordTooShort          = "too_short"
+	PasswordTooLong           = "too_long"
+	PasswordTooFewCharClasses = "too_few_character_classes"
+	PasswordCommon            = "common"
+	PasswordSimilarToAccount  = "similar_to_account"
+	PasswordBreached          = "breached"
+)
+
+// minSimilarityLength is the shortest part of an email address or name that
+// a password may not contain.
+const minSimilarityLength = 3
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// PasswordViolation is one rule a password breaks.
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lists every rule a rejected password breaks, so that
+// the user can fix them all at once.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return "password does not meet the policy: " + strings.Join(messages, "; ")
+}
+
+// BreachedPasswordChecker reports whether a password has appeared in a data
+// breach.
+type BreachedPasswordChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+type PasswordPolicyConfig struct {
+	MinLength int
+	MaxLength int
+	// MinCharacterClasses is how many of lowercase letters, uppercase
+	// letters, digits and symbols a password must mix.
+	MinCharacterClasses int
+	// Denylist holds passwords rejected in addition to the built-in list of
+	// common passwords, such as the product or company name.
+	Denylist []string
+}
+
+// DefaultPasswordPolicyConfig follows NIST SP 800-63B, which favours length
+// and screening over composition rules.
+var DefaultPasswordPolicyConfig = PasswordPolicyConfig{
+	MinLength:           8,
+	MaxLength:           128,
+	MinCharacterClasses: 1,
+}
+
+type PasswordPolicy interface {
+	// Validate returns a *PasswordPolicyError if the password may not be
+	// used by the user. The user needs only Email and Name set.
+	Validate(password string, user *models.User) error
+}
+
+type passwordPolicy struct {
+	config   PasswordPolicyConfig
+	denylist map[string]bool
+	breached BreachedPasswordChecker
+}
+
+// NewPasswordPolicy creates a policy from the config. Breached password
+// screening is skipped when breached is nil.
+func NewPasswordPolicy(config PasswordPolicyConfig, breached BreachedPasswordChecker) PasswordPolicy {
+	denylist := make(map[string]bool)
+	for _, password := range strings.Split(commonPasswordList, "\n") {
+		if password = strings.TrimSpace(password); password != "" {
+			denylist[strings.ToLower(password)] = true
+		}
+	}
+	for _, password := range config.Denylist {
+		if password = strings.TrimSpace(password); password != "" {
+			denylist[strings.ToLower(password)] = true
+		}
+	}
+
+	return &passwordPolicy{
+		config:   config,
+		denylist: denylist,
+		breached: breached,
+	}
+}
+
+func (p *passwordPolicy) Validate(password string, user *models.User) error {
+	var violations []PasswordViolation
+
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		violations = append(violations, PasswordViolation{
+			Code:    PasswordTooShort,
+			Message: fmt.Sprintf("must be at least %d characters", p.config.MinLength),
+		})
+	}
+	if p.config.MaxLength > 0 && length > p.config.MaxLength {
+		violations = append(violations, PasswordViolation{
+			Code:    PasswordTooLong,
+			Message: fmt.Sprintf("must be at most %d characters", p.config.MaxLength),
+		})
+	}
+
+	if characterClasses(password) < p.config.MinCharacterClasses {
+		violations = append(violations, PasswordViolation{
+			Code:    PasswordTooFewCharClasses,
+			Message: fmt.Sprintf("must mix at least %d of lowercase letters, uppercase letters, digits and symbols", p.config.MinCharacterClasses),
+		})
+	}
+
+	if p.denylist[strings.ToLower(password)] {
+		violations = append(violations, PasswordViolation{
+			Code:    PasswordCommon,
+			Message: "is too common",
+		})
+	}
+
+	if user != nil && similarToAccount(password, user) {
+		violations = append(violations, PasswordViolation{
+			Code:    PasswordSimilarToAccount,
+			Message: "must not contain your email address or name",
+		})
+	}
+
+	// Checking the corpus is the slowest rule, and pointless for a password
+	// that is rejected anyway
+	if len(violations) == 0 && p.breached != nil {
+		breached, err := p.breached.IsBreached(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			violations = append(violations, PasswordViolation{
+				Code:    PasswordBreached,
+				Message: "has appeared in a data breach",
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// similarToAccount reports whether the password contains the local part of
+// the email address or a word of the name, or is itself part of either.
+func similarToAccount(password string, user *models.User) bool {
+	password = strings.ToLower(password)
+
+	var parts []string
+	email := strings.ToLower(user.Email)
+	if localPart, _, ok := strings.Cut(email, "@"); ok {
+		parts = append(parts, localPart)
+	}
+	parts = append(parts, strings.Fields(strings.ToLower(user.Name))...)
+
+	for _, part := range parts {
+		if utf8.RuneCountInString(part) >= minSimilarityLength && strings.Contains(password, part) {
+			return true
+		}
+	}
+
+	return strings.Contains(email, password) || strings.Contains(strings.ToLower(user.Name), password)
+}
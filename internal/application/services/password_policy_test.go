@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+)
+
+type fakeBreachedChecker struct {
+	breached map[string]bool
+	err      error
+	calls    int
+}
+
+func (c *fakeBreachedChecker) IsBreached(password string) (bool, error) {
+	c.calls++
+	return c.breached[password], c.err
+}
+
+func violationCodes(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("Validate() error = %v, want a *PasswordPolicyError", err)
+	}
+	codes := make([]string, len(policyErr.Violations))
+	for i, v := range policyErr.Violations {
+		codes[i] = v.Code
+	}
+	return codes
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	config := PasswordPolicyConfig{
+		MinLength:           8,
+		MaxLength:           16,
+		MinCharacterClasses: 2,
+		Denylist:            []string{"Windsurfing1"},
+	}
+	checker := &fakeBreachedChecker{breached: map[string]bool{"Leaked-Secret9": true}}
+	policy := NewPasswordPolicy(config, checker)
+	user := &models.User{Email: "jane.doe@example.com", Name: "Jane Doe"}
+
+	tests := []struct {
+		name     string
+		password string
+		user     *models.User
+		want     []string
+	}{
+		{"acceptable", "Tidal-Basin42", user, nil},
+		{"too short", "aB3$", user, []string{PasswordTooShort}},
+		{"too long", "Abcdefghijklmnopq1", user, []string{PasswordTooLong}},
+		{"length counts characters, not bytes", "Ünïcödé1", user, nil},
+		{"one character class", "tidalbasin", user, []string{PasswordTooFewCharClasses}},
+		{"common password", "Password1", user, []string{PasswordCommon}},
+		{"configured denylist ignores case", "WINDSURFING1", user, []string{PasswordCommon}},
+		{"contains the email local part", "Jane.Doe-2024", user, []string{PasswordSimilarToAccount}},
+		{"contains a word of the name", "Tidal-Jane77", user, []string{PasswordSimilarToAccount}},
+		{"no user to compare with", "Tidal-Jane77", nil, nil},
+		{"breached", "Leaked-Secret9", user, []string{PasswordBreached}},
+		{"several violations at once", "jane", user, []string{PasswordTooShort, PasswordTooFewCharClasses, PasswordSimilarToAccount}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := violationCodes(t, policy.Validate(tt.password, tt.user))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicySkipsBreachCheckForRejectedPasswords(t *testing.T) {
+	checker := &fakeBreachedChecker{}
+	policy := NewPasswordPolicy(DefaultPasswordPolicyConfig, checker)
+
+	if err := policy.Validate("short", nil); err == nil {
+		t.Fatal("Validate() error = nil, want a policy error")
+	}
+	if checker.calls != 0 {
+		t.Errorf("IsBreached called %d times, want 0", checker.calls)
+	}
+}
+
+func TestPasswordPolicyReturnsBreachCheckErrors(t *testing.T) {
+	checkErr := errors.New("corpus unavailable")
+	policy := NewPasswordPolicy(DefaultPasswordPolicyConfig, &fakeBreachedChecker{err: checkErr})
+
+	if err := policy.Validate("Tidal-Basin42", nil); !errors.Is(err, checkErr) {
+		t.Errorf("Validate() error = %v, want %v", err, checkErr)
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const (
+	passwordResetTokenTTL   = time.Hour
+	passwordResetTokenBytes = 32
+	// passwordResetResendInterval is the minimum time between two requested
+	// reset links for the same user. Each link replaces the previous one, so
+	// without it anyone knowing the address could keep the user's link from
+	// working, besides flooding their inbox.
+	passwordResetResendInterval = time.Minute
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+type PasswordResetService interface {
+	// RequestReset emails a reset link to the user with the given address.
+	// Unknown addresses, throttled requests and mail failures are not
+	// reported, so that callers cannot probe for accounts.
+	RequestReset(email string) error
+	// CheckToken reports whether a reset token can still be used.
+	CheckToken(token string) error
+	// ResetPassword sets a new password using an emailed token and signs the
+	// user out everywhere.
+	ResetPassword(token, newPassword string) error
+	// ForceReset replaces the user's password with a random one, signs them
+	// out everywhere and emails them a reset link.
+	ForceReset(userID string) error
+}
+
+type passwordResetService struct {
+	resetRepo   repositories.PasswordResetTokenRepository
+	userRepo    repositories.UserRepository
+	hasher      PasswordHasher
+	policy      PasswordPolicy
+	authService AuthService
+	mailer      Mailer
+	linkURL     string
+}
+
+// NewPasswordResetService sends links to linkURL with the token added as the
+// token query parameter.
+func NewPasswordResetService(resetRepo repositories.PasswordResetTokenRepository, userRepo repositories.UserRepository, hasher PasswordHasher, policy PasswordPolicy, authService AuthService, mailer Mailer, linkURL string) PasswordResetService {
+	return &passwordResetService{
+		resetRepo:   resetRepo,
+		userRepo:    userRepo,
+		hasher:      hasher,
+		policy:      policy,
+		authService: authService,
+		mailer:      mailer,
+		linkURL:     linkURL,
+	}
+}
+
+func (s *passwordResetService) RequestReset(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	sendUnobserved("password reset email", func() error {
+		return s.sendRequestedLink(user)
+	})
+	return nil
+}
+
+// sendRequestedLink sends a link the user asked for, unless one was sent
+// within passwordResetResendInterval.
+func (s *passwordResetService) sendRequestedLink(user *models.User) error {
+	claimed, err := s.userRepo.ClaimPasswordResetEmail(user.ID, passwordResetResendInterval)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+	return s.sendResetLink(user)
+}
+
+func (s *passwordResetService) CheckToken(token string) error {
+	reset, err := s.resetRepo.FindValid(hashToken(token))
+	if err != nil {
+		return err
+	}
+	if reset == nil {
+		return ErrInvalidResetToken
+	}
+	return nil
+}
+
+func (s *passwordResetService) ForceReset(userID string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	password, err := generateOpaqueToken(passwordResetTokenBytes)
+	if err != nil {
+		return err
+	}
+	passwordHash, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(user.ID, passwordHash); err != nil {
+		return err
+	}
+
+	if err := s.authService.RevokeAllTokens(user.ID); err != nil {
+		return err
+	}
+
+	return s.sendResetLink(user)
+}
+
+func (s *passwordResetService) sendResetLink(user *models.User) error {
+	// Only the most recently requested link works
+	if err := s.resetRepo.InvalidateForUser(user.ID); err != nil {
+		return err
+	}
+
+	token, err := generateOpaqueToken(passwordResetTokenBytes)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.resetRepo.Create(&models.PasswordResetToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(passwordResetTokenTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	link := appendQuery(s.linkURL, url.Values{"token": {token}})
+	return s.mailer.Send(&Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes.\n\n%s\n\nIf you did not ask to reset your password, you can ignore this email.\n",
+			user.Name, int(passwordResetTokenTTL.Minutes()), link,
+		),
+	})
+}
+
+func (s *passwordResetService) ResetPassword(token, newPassword string) error {
+	// Check the password before consuming the token, so that the user can
+	// try again with a better one
+	reset, err := s.resetRepo.FindValid(hashToken(token))
+	if err != nil {
+		return err
+	}
+	if reset == nil {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.FindByID(reset.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidResetToken
+	}
+	if err := s.policy.Validate(newPassword, user); err != nil {
+		return err
+	}
+
+	reset, err = s.resetRepo.Consume(hashToken(token))
+	if err != nil {
+		return err
+	}
+	if reset == nil {
+		return ErrInvalidResetToken
+	}
+
+	passwordHash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(reset.UserID, passwordHash); err != nil {
+		return err
+	}
+
+	if err := s.resetRepo.InvalidateForUser(reset.UserID); err != nil {
+		return err
+	}
+
+	// Whoever knew the old password must not stay signed in
+	return s.authService.RevokeAllTokens(reset.UserID)
+}
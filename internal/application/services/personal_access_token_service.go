@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const maxPersonalAccessTokenTTL = 365 * 24 * time.Hour
+
+var ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
+
+type PersonalAccessTokenService interface {
+	// Create mints a scoped token for the user. The token string is returned
+	// only once; afterwards it can only be listed and revoked.
+	Create(userID, name string, scopes []models.Permission, ttl time.Duration) (string, *models.PersonalAccessToken, error)
+	List(userID string) ([]models.PersonalAccessToken, error)
+	Revoke(userID, id string) error
+}
+
+type personalAccessTokenService struct {
+	tokenRepo   repositories.PersonalAccessTokenRepository
+	authService AuthService
+}
+
+func NewPersonalAccessTokenService(tokenRepo repositories.PersonalAccessTokenRepository, authService AuthService) PersonalAccessTokenService {
+	return &personalAccessTokenService{
+		tokenRepo:   tokenRepo,
+		authService: authService,
+	}
+}
+
+func (s *personalAccessTokenService) Create(userID, name string, scopes []models.Permission, ttl time.Duration) (string, *models.PersonalAccessToken, error) {
+	if len(scopes) == 0 {
+		return "", nil, errors.New("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			return "", nil, errors.New("unknown scope " + string(scope))
+		}
+	}
+	if ttl <= 0 || ttl > maxPersonalAccessTokenTTL {
+		return "", nil, errors.New("token lifetime must be between 1 and 365 days")
+	}
+
+	now := time.Now()
+	pat := &models.PersonalAccessToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Scopes:    scopes,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	token, err := s.authService.GenerateToken(userID, TokenOptions{
+		TokenID: pat.ID,
+		Scopes:  scopes,
+		TTL:     ttl,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := s.tokenRepo.Create(pat); err != nil {
+		return "", nil, err
+	}
+
+	return token, pat, nil
+}
+
+func (s *personalAccessTokenService) List(userID string) ([]models.PersonalAccessToken, error) {
+	return s.tokenRepo.FindByUserID(userID)
+}
+
+func (s *personalAccessTokenService) Revoke(userID, id string) error {
+	pat, err := s.tokenRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if pat == nil || pat.UserID != userID || pat.RevokedAt != nil {
+		return ErrPersonalAccessTokenNotFound
+	}
+
+	if err := s.tokenRepo.Revoke(id); err != nil {
+		return err
+	}
+
+	return s.authService.RevokeToken(&AccessClaims{
+		TokenID:   pat.ID,
+		UserID:    pat.UserID,
+		ExpiresAt: pat.ExpiresAt,
+	})
+}
@@ -1,42 +1,466 @@
 package services
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
 	"github.com/prakoso-id/go-windsurf/internal/domain/models"
 	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
 )
 
+const (
+	defaultProductPageSize = 20
+	maxProductPageSize     = 100
+
+	defaultProductSort = "-created_at"
+
+	// maxProductSearchPage keeps the offset of deep search pages in range;
+	// nobody reads that far into ranked results
+	maxProductSearchPage = 1000
+
+	defaultSuggestionLimit = 10
+	maxSuggestionLimit     = 25
+	// minSuggestionInputLength avoids matching nearly every name on the
+	// first keystroke
+	minSuggestionInputLength = 2
+	// minSuggestionScore lets through names with a typo or two
+	minSuggestionScore = 0.3
+
+	maxSecondaryCategories = 10
+)
+
+var (
+	ErrInvalidProductQuery      = errors.New("invalid product query")
+	ErrInvalidProductCategories = errors.New("invalid product categories")
+)
+
+// ProductQuery selects a page of products. Category includes products in
+// its subcategories. Sort names a field, prefixed with "-" for descending
+// order, and Cursor is the NextCursor of the previous page, which must have
+// been fetched with the same sort.
+type ProductQuery struct {
+	Name          string     `form:"name"`
+	Category      *uint      `form:"category"`
+	MinPrice      *float64   `form:"min_price"`
+	MaxPrice      *float64   `form:"max_price"`
+	CreatedAfter  *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort          string     `form:"sort"`
+	Cursor        string     `form:"cursor"`
+	Limit         int        `form:"limit"`
+}
+
+// ProductPage is one page of products. NextCursor is empty on the last page.
+type ProductPage struct {
+	Products   []models.Product
+	NextCursor string
+	Total      int
+	Limit      int
+}
+
+// ProductSearchQuery selects a page of full-text search results. Page
+// numbers start at 1.
+type ProductSearchQuery struct {
+	Q        string `form:"q" binding:"required"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+}
+
+type ProductSearchPage struct {
+	Hits     []models.ProductSearchHit
+	Total    int
+	Page     int
+	PageSize int
+}
+
+// ProductSuggestQuery asks for product names resembling what the user has
+// typed so far.
+type ProductSuggestQuery struct {
+	Q     string `form:"q" binding:"required"`
+	Limit int    `form:"limit"`
+}
+
+// productCursor is encoded into the opaque cursor handed to clients. The
+// sort is kept so that a cursor cannot be used with a different order.
+type productCursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    uint            `json:"id"`
+}
+
+// ProductService manages products. Products are returned with their
+// categories and the breadcrumbs of their primary category.
 type ProductService interface {
+	// CreateProduct creates the product in its primary and secondary
+	// categories.
 	CreateProduct(product *models.Product) error
 	GetProduct(id uint) (*models.Product, error)
-	ListProducts() ([]models.Product, error)
+	ListProducts(query ProductQuery) (*ProductPage, error)
+	// SearchProducts finds products by name and description, most relevant
+	// first. The query uses web search syntax.
+	SearchProducts(query ProductSearchQuery) (*ProductSearchPage, error)
+	// SuggestProductNames returns product names resembling partial or
+	// misspelled input, best match first.
+	SuggestProductNames(query ProductSuggestQuery) ([]models.ProductSuggestion, error)
+	// UpdateProduct replaces the product, including its categories.
 	UpdateProduct(product *models.Product) error
 	DeleteProduct(id uint) error
 }
 
 type productService struct {
-	productRepo repositories.ProductRepository
+	productRepo  repositories.ProductRepository
+	categoryRepo repositories.CategoryRepository
 }
 
-func NewProductService(productRepo repositories.ProductRepository) ProductService {
-	return &productService{productRepo: productRepo}
+func NewProductService(productRepo repositories.ProductRepository, categoryRepo repositories.CategoryRepository) ProductService {
+	return &productService{
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+	}
 }
 
 func (s *productService) CreateProduct(product *models.Product) error {
-	return s.productRepo.Create(product)
+	if err := s.checkCategories(product); err != nil {
+		return err
+	}
+	err := s.productRepo.Create(product)
+	if errors.Is(err, repositories.ErrCategoryMissing) {
+		return fmt.Errorf("%w: a category no longer exists", ErrInvalidProductCategories)
+	}
+	if err != nil {
+		return err
+	}
+	return s.attachCategories([]*models.Product{product})
 }
 
 func (s *productService) GetProduct(id uint) (*models.Product, error) {
-	return s.productRepo.FindByID(id)
+	product, err := s.productRepo.FindByID(id)
+	if err != nil || product == nil {
+		return nil, err
+	}
+	if err := s.attachCategories([]*models.Product{product}); err != nil {
+		return nil, err
+	}
+	return product, nil
 }
 
-func (s *productService) ListProducts() ([]models.Product, error) {
-	return s.productRepo.FindAll()
+func (s *productService) ListProducts(query ProductQuery) (*ProductPage, error) {
+	sort := query.Sort
+	if sort == "" {
+		sort = defaultProductSort
+	}
+	field := repositories.ProductSortField(strings.TrimPrefix(sort, "-"))
+	switch field {
+	case repositories.ProductSortCreatedAt, repositories.ProductSortName, repositories.ProductSortPrice:
+	default:
+		return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalidProductQuery, field)
+	}
+
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return nil, fmt.Errorf("%w: min_price is greater than max_price", ErrInvalidProductQuery)
+	}
+	if query.CreatedAfter != nil && query.CreatedBefore != nil && !query.CreatedAfter.Before(*query.CreatedBefore) {
+		return nil, fmt.Errorf("%w: created_after is not before created_before", ErrInvalidProductQuery)
+	}
+
+	limit := query.Limit
+	if limit < 1 {
+		limit = defaultProductPageSize
+	}
+	if limit > maxProductPageSize {
+		limit = maxProductPageSize
+	}
+
+	filter := repositories.ProductFilter{
+		NameContains:  query.Name,
+		MinPrice:      query.MinPrice,
+		MaxPrice:      query.MaxPrice,
+		CreatedAfter:  query.CreatedAfter,
+		CreatedBefore: query.CreatedBefore,
+		SortField:     field,
+		Descending:    strings.HasPrefix(sort, "-"),
+		// One extra row tells whether there is a next page
+		Limit: limit + 1,
+	}
+	if query.Category != nil {
+		category, err := s.categoryRepo.FindByID(*query.Category)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			return nil, ErrCategoryNotFound
+		}
+		filter.CategoryPath = category.Path
+	}
+	if query.Cursor != "" {
+		after, err := decodeProductCursor(query.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	products, total, err := s.productRepo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ProductPage{
+		Products: products,
+		Total:    total,
+		Limit:    limit,
+	}
+	if len(products) > limit {
+		page.Products = products[:limit]
+		page.NextCursor, err = encodeProductCursor(&products[limit-1], sort, field)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	list := make([]*models.Product, len(page.Products))
+	for i := range page.Products {
+		list[i] = &page.Products[i]
+	}
+	if err := s.attachCategories(list); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (s *productService) SearchProducts(query ProductSearchQuery) (*ProductSearchPage, error) {
+	q := strings.TrimSpace(query.Q)
+	if q == "" {
+		return nil, fmt.Errorf("%w: empty search", ErrInvalidProductQuery)
+	}
+
+	page, pageSize := query.Page, query.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if page > maxProductSearchPage {
+		return nil, fmt.Errorf("%w: page must be at most %d", ErrInvalidProductQuery, maxProductSearchPage)
+	}
+	if pageSize < 1 {
+		pageSize = defaultProductPageSize
+	}
+	if pageSize > maxProductPageSize {
+		pageSize = maxProductPageSize
+	}
+
+	hits, total, err := s.productRepo.Search(repositories.ProductSearch{
+		Query:  q,
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]*models.Product, len(hits))
+	for i := range hits {
+		products[i] = &hits[i].Product
+	}
+	if err := s.attachCategories(products); err != nil {
+		return nil, err
+	}
+
+	return &ProductSearchPage{
+		Hits:     hits,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func (s *productService) SuggestProductNames(query ProductSuggestQuery) ([]models.ProductSuggestion, error) {
+	input := strings.TrimSpace(query.Q)
+	if utf8.RuneCountInString(input) < minSuggestionInputLength {
+		return []models.ProductSuggestion{}, nil
+	}
+
+	limit := query.Limit
+	if limit < 1 {
+		limit = defaultSuggestionLimit
+	}
+	if limit > maxSuggestionLimit {
+		limit = maxSuggestionLimit
+	}
+
+	return s.productRepo.SuggestNames(repositories.ProductSuggestionQuery{
+		Input:    input,
+		MinScore: minSuggestionScore,
+		Limit:    limit,
+	})
+}
+
+func encodeProductCursor(product *models.Product, sort string, field repositories.ProductSortField) (string, error) {
+	var value interface{}
+	switch field {
+	case repositories.ProductSortName:
+		value = product.Name
+	case repositories.ProductSortPrice:
+		value = product.Price
+	default:
+		value = product.CreatedAt
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(productCursor{Sort: sort, Value: raw, ID: product.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeProductCursor(cursor, sort string) (*repositories.ProductCursor, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", ErrInvalidProductQuery)
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	var decoded productCursor
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, invalid
+	}
+	if decoded.Sort != sort {
+		return nil, fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidProductQuery, decoded.Sort)
+	}
+
+	var value interface{}
+	switch repositories.ProductSortField(strings.TrimPrefix(sort, "-")) {
+	case repositories.ProductSortName:
+		var name string
+		err = json.Unmarshal(decoded.Value, &name)
+		value = name
+	case repositories.ProductSortPrice:
+		var price float64
+		err = json.Unmarshal(decoded.Value, &price)
+		value = price
+	default:
+		var createdAt time.Time
+		err = json.Unmarshal(decoded.Value, &createdAt)
+		value = createdAt
+	}
+	if err != nil {
+		return nil, invalid
+	}
+
+	return &repositories.ProductCursor{SortValue: value, ID: decoded.ID}, nil
 }
 
 func (s *productService) UpdateProduct(product *models.Product) error {
-	return s.productRepo.Update(product)
+	if err := s.checkCategories(product); err != nil {
+		return err
+	}
+	err := s.productRepo.Update(product)
+	if errors.Is(err, repositories.ErrCategoryMissing) {
+		return fmt.Errorf("%w: a category no longer exists", ErrInvalidProductCategories)
+	}
+	if err != nil {
+		return err
+	}
+	return s.attachCategories([]*models.Product{product})
 }
 
 func (s *productService) DeleteProduct(id uint) error {
 	return s.productRepo.Delete(id)
 }
+
+// checkCategories makes sure the product's categories exist and that it has
+// a primary category if it has secondary ones. Repeated secondary
+// categories are dropped.
+func (s *productService) checkCategories(product *models.Product) error {
+	var secondaryIDs []uint
+	seen := make(map[uint]bool)
+	for _, id := range product.SecondaryCategoryIDs {
+		if !seen[id] {
+			seen[id] = true
+			secondaryIDs = append(secondaryIDs, id)
+		}
+	}
+	product.SecondaryCategoryIDs = secondaryIDs
+
+	if product.PrimaryCategoryID == nil {
+		if len(secondaryIDs) > 0 {
+			return fmt.Errorf("%w: secondary categories need a primary category", ErrInvalidProductCategories)
+		}
+		return nil
+	}
+	if seen[*product.PrimaryCategoryID] {
+		return fmt.Errorf("%w: the primary category is also a secondary one", ErrInvalidProductCategories)
+	}
+	if len(secondaryIDs) > maxSecondaryCategories {
+		return fmt.Errorf("%w: at most %d secondary categories", ErrInvalidProductCategories, maxSecondaryCategories)
+	}
+
+	ids := append([]uint{*product.PrimaryCategoryID}, secondaryIDs...)
+	categories, err := s.categoryRepo.FindByIDs(ids)
+	if err != nil {
+		return err
+	}
+	found := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		found[category.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return fmt.Errorf("%w: category %d does not exist", ErrInvalidProductCategories, id)
+		}
+	}
+	return nil
+}
+
+// attachCategories fills in the categories and breadcrumbs of the products.
+func (s *productService) attachCategories(products []*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(products))
+	byID := make(map[uint]*models.Product, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+		byID[product.ID] = product
+		product.PrimaryCategoryID = nil
+		product.SecondaryCategoryIDs = nil
+		product.Breadcrumbs = nil
+	}
+
+	placements, err := s.categoryRepo.FindProductCategories(ids)
+	if err != nil {
+		return err
+	}
+
+	var primaryPaths []string
+	primaryPath := make(map[uint]string)
+	for _, placement := range placements {
+		product := byID[placement.ProductID]
+		if placement.Primary {
+			categoryID := placement.CategoryID
+			product.PrimaryCategoryID = &categoryID
+			primaryPath[product.ID] = placement.Path
+			primaryPaths = append(primaryPaths, placement.Path)
+		} else {
+			product.SecondaryCategoryIDs = append(product.SecondaryCategoryIDs, placement.CategoryID)
+		}
+	}
+
+	breadcrumbs, err := loadBreadcrumbs(s.categoryRepo, primaryPaths)
+	if err != nil {
+		return err
+	}
+	for productID, path := range primaryPath {
+		byID[productID].Breadcrumbs = breadcrumbs[path]
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+func TestProductCursorRoundTrip(t *testing.T) {
+	product := &models.Product{
+		ID:        42,
+		Name:      "Office Chair",
+		Price:     129.5,
+		CreatedAt: time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.UTC),
+	}
+
+	tests := []struct {
+		sort  string
+		field repositories.ProductSortField
+		want  interface{}
+	}{
+		{"created_at", repositories.ProductSortCreatedAt, product.CreatedAt},
+		{"-created_at", repositories.ProductSortCreatedAt, product.CreatedAt},
+		{"name", repositories.ProductSortName, product.Name},
+		{"-price", repositories.ProductSortPrice, product.Price},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			cursor, err := encodeProductCursor(product, tt.sort, tt.field)
+			if err != nil {
+				t.Fatalf("encodeProductCursor() error = %v", err)
+			}
+
+			decoded, err := decodeProductCursor(cursor, tt.sort)
+			if err != nil {
+				t.Fatalf("decodeProductCursor() error = %v", err)
+			}
+			if decoded.ID != product.ID {
+				t.Errorf("ID = %d, want %d", decoded.ID, product.ID)
+			}
+			if createdAt, ok := tt.want.(time.Time); ok {
+				got, ok := decoded.SortValue.(time.Time)
+				if !ok || !got.Equal(createdAt) {
+					t.Errorf("SortValue = %v, want %v", decoded.SortValue, createdAt)
+				}
+				return
+			}
+			if !reflect.DeepEqual(decoded.SortValue, tt.want) {
+				t.Errorf("SortValue = %#v, want %#v", decoded.SortValue, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeProductCursorRejectsInvalidCursors(t *testing.T) {
+	product := &models.Product{ID: 7, Name: "Desk", Price: 10}
+	nameCursor, err := encodeProductCursor(product, "name", repositories.ProductSortName)
+	if err != nil {
+		t.Fatalf("encodeProductCursor() error = %v", err)
+	}
+
+	encode := func(data string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(data))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   string
+	}{
+		{"wrong sort", nameCursor, "price"},
+		{"reversed sort", nameCursor, "-name"},
+		{"not base64", "!!not-base64!!", "name"},
+		{"not JSON", encode("garbage"), "name"},
+		{"empty", "", "name"},
+		{"value of the wrong type", encode(`{"s":"price","v":"cheap","id":7}`), "price"},
+		{"malformed time", encode(`{"s":"created_at","v":"yesterday","id":7}`), "created_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeProductCursor(tt.cursor, tt.sort)
+			if !errors.Is(err, ErrInvalidProductQuery) {
+				t.Errorf("decodeProductCursor() error = %v, want ErrInvalidProductQuery", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 appendix B test vectors,
+// "12345678901234567890", base32 encoded.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	// The RFC lists 8-digit codes; 6-digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			if got := totpCode(key, tt.unix/totpPeriod); got != tt.want {
+				t.Errorf("totpCode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		ok       bool
+		wantStep int64
+	}{
+		{"current code", rfc6238Secret, "050471", true, step},
+		{"lowercase secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "050471", true, step},
+		{"previous step", rfc6238Secret, totpCodeAt(t, step-1), true, step - 1},
+		{"next step", rfc6238Secret, totpCodeAt(t, step+1), true, step + 1},
+		{"two steps ago", rfc6238Secret, totpCodeAt(t, step-2), false, 0},
+		{"two steps ahead", rfc6238Secret, totpCodeAt(t, step+2), false, 0},
+		{"wrong code", rfc6238Secret, "123456", false, 0},
+		{"8-digit code", rfc6238Secret, "14050471", false, 0},
+		{"empty code", rfc6238Secret, "", false, 0},
+		{"invalid secret", "not base32!", "050471", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := validateTOTP(tt.secret, tt.code, now)
+			if ok != tt.ok || gotStep != tt.wantStep {
+				t.Errorf("validateTOTP() = (%d, %v), want (%d, %v)", gotStep, ok, tt.wantStep, tt.ok)
+			}
+		})
+	}
+}
+
+func totpCodeAt(t *testing.T, step int64) string {
+	t.Helper()
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatalf("DecodeString() error = %v", err)
+	}
+	return totpCode(key, step)
+}
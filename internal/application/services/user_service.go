@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,21 +10,50 @@ import (
 	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
 )
 
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrEmailTaken         = errors.New("email already registered")
+)
+
 type UserService interface {
 	Register(email, password, name string) (*models.User, error)
 	Login(email, password string) (string, error) // Returns JWT token
 	GetUserByID(id string) (*models.User, error)
+	VerifyPassword(id, password string) error
 	UpdateUser(id, email, name string) error
-	UpdatePassword(id, newPassword string) error
+	// ChangePassword sets a new password for a signed-in user. Pending reset
+	// links stop working and every session but the current one ends, so
+	// that a password changed after a compromise locks the intruder out.
+	ChangePassword(id, currentSessionID, newPassword string) error
+	UpdateRole(id string, role models.Role) error
 	DeleteUser(id string) error
 }
 
 type userService struct {
-	userRepo repositories.UserRepository
+	userRepo    repositories.UserRepository
+	resetRepo   repositories.PasswordResetTokenRepository
+	authService AuthService
+	hasher      PasswordHasher
+	policy      PasswordPolicy
+	// dummyHash is verified against when the email is unknown, so that
+	// response times do not reveal which emails are registered
+	dummyHash string
 }
 
-func NewUserService(userRepo repositories.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo repositories.UserRepository, resetRepo repositories.PasswordResetTokenRepository, authService AuthService, hasher PasswordHasher, policy PasswordPolicy) (UserService, error) {
+	dummyHash, err := hasher.Hash(uuid.New().String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &userService{
+		userRepo:    userRepo,
+		resetRepo:   resetRepo,
+		authService: authService,
+		hasher:      hasher,
+		policy:      policy,
+		dummyHash:   dummyHash,
+	}, nil
 }
 
 func (s *userService) Register(email, password, name string) (*models.User, error) {
@@ -32,16 +62,26 @@ func (s *userService) Register(email, password, name string) (*models.User, erro
 		return nil, err
 	}
 	if existingUser != nil {
-		return nil, errors.New("email already registered")
+		return nil, ErrEmailTaken
+	}
+
+	if err := s.policy.Validate(password, &models.User{Email: email, Name: name}); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, err
 	}
 
 	user := &models.User{
-		ID:        uuid.New().String(),
-		Email:     email,
-		Password:  password,
-		Name:      name,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:           uuid.New().String(),
+		Email:        email,
+		PasswordHash: passwordHash,
+		Name:         name,
+		Role:         models.RoleViewer,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
@@ -52,12 +92,22 @@ func (s *userService) Register(email, password, name string) (*models.User, erro
 }
 
 func (s *userService) Login(email, password string) (string, error) {
-	user, err := s.userRepo.FindByEmailAndPassword(email, password)
+	user, err := s.userRepo.FindByEmail(email)
 	if err != nil {
 		return "", err
 	}
 	if user == nil {
-		return "", errors.New("invalid credentials")
+		s.hasher.Verify(password, s.dummyHash)
+		return "", ErrInvalidCredentials
+	}
+
+	if err := s.checkPassword(user, password); err != nil {
+		return "", err
+	}
+	// Checked after the password so that the error reveals nothing to
+	// someone who does not know it
+	if user.DisabledAt != nil {
+		return "", ErrAccountDisabled
 	}
 
 	return user.ID, nil
@@ -67,6 +117,41 @@ func (s *userService) GetUserByID(id string) (*models.User, error) {
 	return s.userRepo.FindByID(id)
 }
 
+func (s *userService) VerifyPassword(id, password string) error {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	return s.checkPassword(user, password)
+}
+
+// checkPassword verifies the user's password and, once it is known to be
+// correct, upgrades a legacy or weaker hash to the current parameters.
+func (s *userService) checkPassword(user *models.User, password string) error {
+	ok, err := s.hasher.Verify(password, user.PasswordHash)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidCredentials
+	}
+
+	if s.hasher.NeedsRehash(user.PasswordHash) {
+		// The password was right, so a failed upgrade must not fail the login
+		if passwordHash, err := s.hasher.Hash(password); err != nil {
+			log.Printf("Failed to rehash password for user %s: %v", user.ID, err)
+		} else if err := s.userRepo.UpdatePassword(user.ID, passwordHash); err != nil {
+			log.Printf("Failed to rehash password for user %s: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
+
 func (s *userService) UpdateUser(id, email, name string) error {
 	user, err := s.userRepo.FindByID(id)
 	if err != nil {
@@ -76,6 +161,16 @@ func (s *userService) UpdateUser(id, email, name string) error {
 		return errors.New("user not found")
 	}
 
+	if email != user.Email {
+		existingUser, err := s.userRepo.FindByEmail(email)
+		if err != nil {
+			return err
+		}
+		if existingUser != nil {
+			return ErrEmailTaken
+		}
+	}
+
 	user.Email = email
 	user.Name = name
 	user.UpdatedAt = time.Now()
@@ -83,7 +178,7 @@ func (s *userService) UpdateUser(id, email, name string) error {
 	return s.userRepo.Update(user)
 }
 
-func (s *userService) UpdatePassword(id, newPassword string) error {
+func (s *userService) ChangePassword(id, currentSessionID, newPassword string) error {
 	user, err := s.userRepo.FindByID(id)
 	if err != nil {
 		return err
@@ -92,7 +187,31 @@ func (s *userService) UpdatePassword(id, newPassword string) error {
 		return errors.New("user not found")
 	}
 
-	return s.userRepo.UpdatePassword(id, newPassword)
+	if err := s.policy.Validate(newPassword, user); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(id, passwordHash); err != nil {
+		return err
+	}
+
+	if err := s.resetRepo.InvalidateForUser(id); err != nil {
+		return err
+	}
+	return s.authService.RevokeOtherSessions(id, currentSessionID)
+}
+
+func (s *userService) UpdateRole(id string, role models.Role) error {
+	if !role.Valid() {
+		return errors.New("invalid role")
+	}
+
+	return s.userRepo.UpdateRole(id, role)
 }
 
 func (s *userService) DeleteUser(id string) error {
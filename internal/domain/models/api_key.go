@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// APIKey lets machines authenticate as a user without a password. Only the
+// SHA-256 hash of the key is stored; the prefix is kept in clear text so that
+// users can tell their keys apart.
+type APIKey struct {
+	ID         string       `json:"id"`
+	UserID     string       `json:"user_id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	KeyHash    string       `json:"-"`
+	Scopes     []Permission `json:"scopes"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	RevokedAt  *time.Time   `json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+const (
+	AuditLoginLocked   = "login.locked"
+	AuditLoginUnlocked = "login.unlocked"
+
+	AuditUserDisabled        = "user.disabled"
+	AuditUserEnabled         = "user.enabled"
+	AuditUserDeleted         = "user.deleted"
+	AuditUserRoleChanged     = "user.role_changed"
+	AuditPasswordResetForced = "user.password_reset_forced"
+
+	AuditEmailChangeRequested = "user.email_change_requested"
+	AuditEmailChanged         = "user.email_changed"
+	AuditEmailChangeReverted  = "user.email_change_reverted"
+
+	AuditDataExported      = "user.data_exported"
+	AuditDeletionScheduled = "user.deletion_scheduled"
+	AuditDeletionCancelled = "user.deletion_cancelled"
+
+	AuditImpersonationStarted = "impersonation.started"
+	AuditImpersonatedRequest  = "impersonation.request"
+)
+
+// AuditEvent records a security relevant action. UserID is the account the
+// event is about and ActorID the user who caused it, when they differ.
+type AuditEvent struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	UserID    *string           `json:"user_id,omitempty"`
+	ActorID   *string           `json:"actor_id,omitempty"`
+	IPAddress string            `json:"ip_address,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Category is a node in the product category tree. Path lists the IDs from
+// the root down to the category, as in "/1/4/9/", and Depth is 0 for
+// top-level categories.
+type Category struct {
+	ID          uint      `json:"id"`
+	ParentID    *uint     `json:"parent_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Path        string    `json:"-"`
+	Depth       int       `json:"depth"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// Breadcrumbs lead from the root to the category, which comes last.
+	Breadcrumbs []Breadcrumb `json:"breadcrumbs,omitempty"`
+}
+
+// CategoryTreeNode is a category along with its subcategories.
+type CategoryTreeNode struct {
+	Category
+	Children []CategoryTreeNode `json:"children"`
+}
+
+// Breadcrumb is one step on the way from the root of the category tree.
+type Breadcrumb struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// ProductCategory places a product in a category. Path is the category's.
+type ProductCategory struct {
+	ProductID  uint
+	CategoryID uint
+	Primary    bool
+	Path       string
+}
+
+// CategoryPathIDs returns the IDs in a category path, from the root down.
+func CategoryPathIDs(path string) []uint {
+	var ids []uint
+	for _, field := range strings.Split(strings.Trim(path, "/"), "/") {
+		id, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCategoryPathIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want []uint
+	}{
+		{"top level", "/1/", []uint{1}},
+		{"nested", "/1/4/9/", []uint{1, 4, 9}},
+		{"empty", "", nil},
+		{"root only", "/", nil},
+		{"missing slashes", "3/5", []uint{3, 5}},
+		{"non-numeric segments are skipped", "/1/x/9/", []uint{1, 9}},
+		{"out of range segments are skipped", "/1/99999999999/2/", []uint{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CategoryPathIDs(tt.path); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CategoryPathIDs(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
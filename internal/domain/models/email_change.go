@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// EmailChange is a request to change a user's email address. It takes effect
+// once confirmed with the token sent to the new address, and the token sent
+// to the old address cancels or undoes it. Only hashes of the tokens are
+// stored.
+type EmailChange struct {
+	ID               string     `json:"id"`
+	UserID           string     `json:"user_id"`
+	OldEmail         string     `json:"old_email"`
+	NewEmail         string     `json:"new_email"`
+	ConfirmTokenHash string     `json:"-"`
+	RevertTokenHash  string     `json:"-"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevertExpiresAt  time.Time  `json:"revert_expires_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ConfirmedAt      *time.Time `json:"confirmed_at,omitempty"`
+	RevertedAt       *time.Time `json:"reverted_at,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// LoginThrottle counts recent failed logins for an account or a client IP.
+type LoginThrottle struct {
+	Key           string     `json:"key"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
+
+// Locked reports whether logins for the key are refused at t.
+func (t *LoginThrottle) Locked(at time.Time) bool {
+	return t.LockedUntil != nil && at.Before(*t.LockedUntil)
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// TOTPCredential holds a user's RFC 6238 shared secret. It is pending until
+// EnabledAt is set by confirming a first code.
+type TOTPCredential struct {
+	UserID       string     `json:"user_id"`
+	Secret       string     `json:"-"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep int64      `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (c *TOTPCredential) Enabled() bool {
+	return c.EnabledAt != nil
+}
+
+// RecoveryCode is a single-use fallback for a lost authenticator. Only its
+// hash is stored.
+type RecoveryCode struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
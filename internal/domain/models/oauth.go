@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// OAuthClient is a third-party application that can obtain access tokens
+// through the OAuth2 authorization server. Only the SHA-256 hash of a
+// confidential client's secret is stored.
+type OAuthClient struct {
+	ID           string       `json:"client_id"`
+	OwnerID      string       `json:"owner_id"`
+	Name         string       `json:"name"`
+	SecretHash   string       `json:"-"`
+	RedirectURIs []string     `json:"redirect_uris"`
+	Scopes       []Permission `json:"scopes"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// Confidential reports whether the client authenticates with a secret.
+func (c *OAuthClient) Confidential() bool {
+	return c.SecretHash != ""
+}
+
+// AllowsRedirectURI reports whether uri exactly matches a registered one.
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+	for _, registered := range c.RedirectURIs {
+		if registered == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// OAuthAuthorizationCode is a short-lived, single-use grant issued after the
+// user approves a client. It is bound to the PKCE challenge of the request.
+type OAuthAuthorizationCode struct {
+	CodeHash      string
+	ClientID      string
+	UserID        string
+	RedirectURI   string
+	Scopes        []Permission
+	CodeChallenge string
+	ExpiresAt     time.Time
+	CreatedAt     time.Time
+}
+
+// OAuthConsent remembers the scopes a user has granted to a client so that
+// they are not asked again.
+type OAuthConsent struct {
+	UserID    string       `json:"user_id"`
+	ClientID  string       `json:"client_id"`
+	Scopes    []Permission `json:"scopes"`
+	GrantedAt time.Time    `json:"granted_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// OIDCLoginState remembers an OpenID Connect login between the redirect to
+// the provider and its callback. It is used once.
+type OIDCLoginState struct {
+	State        string    `json:"-"`
+	Provider     string    `json:"provider"`
+	Nonce        string    `json:"-"`
+	CodeVerifier string    `json:"-"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// UserIdentity links a local user to their account at an external identity
+// provider, identified by the provider's stable subject identifier.
+type UserIdentity struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
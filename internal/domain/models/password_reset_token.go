@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PasswordResetToken is emailed to a user who forgot their password. Only the
+// SHA-256 hash of the token is stored and each token can be used once.
+type PasswordResetToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
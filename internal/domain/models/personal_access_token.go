@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// PersonalAccessToken records a long-lived, scoped access token minted by a
+// user. Its ID is the jti of the token, which is only shown once.
+type PersonalAccessToken struct {
+	ID        string       `json:"id"`
+	UserID    string       `json:"user_id"`
+	Name      string       `json:"name"`
+	Scopes    []Permission `json:"scopes"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	CreatedAt time.Time    `json:"created_at"`
+	RevokedAt *time.Time   `json:"revoked_at,omitempty"`
+}
@@ -5,10 +5,40 @@ import (
 )
 
 type Product struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Name        string    `json:"name" binding:"required"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price" binding:"required"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price" binding:"required"`
+	// OwnerID is the user who created the product. It is cleared when their
+	// account is deleted.
+	OwnerID *string `json:"owner_id,omitempty"`
+	// PrimaryCategoryID and SecondaryCategoryIDs place the product in the
+	// category tree. Breadcrumbs lead from the root to the primary category
+	// and are ignored on input.
+	PrimaryCategoryID    *uint        `json:"primary_category_id,omitempty"`
+	SecondaryCategoryIDs []uint       `json:"secondary_category_ids,omitempty"`
+	Breadcrumbs          []Breadcrumb `json:"breadcrumbs,omitempty"`
+	CreatedAt            time.Time    `json:"created_at"`
+	UpdatedAt            time.Time    `json:"updated_at"`
+}
+
+// ProductSearchHit is a product matching a full-text search. The highlights
+// repeat the name and an excerpt of the description as HTML, escaped and
+// with the matching words wrapped in <mark> tags.
+type ProductSearchHit struct {
+	Product
+	Rank       float64           `json:"rank"`
+	Highlights ProductHighlights `json:"highlights"`
+}
+
+type ProductHighlights struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ProductSuggestion is a product name offered while the user types. Score is
+// the trigram word similarity between the input and the name, from 0 to 1.
+type ProductSuggestion struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
 }
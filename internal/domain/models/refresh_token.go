@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RefreshToken is a single link in a rotation chain. Every token issued from
+// the same login shares a FamilyID so that the whole chain can be revoked
+// when an already rotated token is presented again.
+type RefreshToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	FamilyID   string     `json:"family_id"`
+	TokenHash  string     `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy *string    `json:"replaced_by,omitempty"`
+}
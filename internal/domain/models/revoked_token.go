@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RevokedToken is a denylist entry for a single access token, keyed by its
+// JWT ID. Entries only need to be kept until the token would have expired.
+type RevokedToken struct {
+	JTI       string    `json:"jti"`
+	UserID    string    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
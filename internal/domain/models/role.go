@@ -0,0 +1,56 @@
+package models
+
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+type Permission string
+
+const (
+	PermissionProductsRead  Permission = "products:read"
+	PermissionProductsWrite Permission = "products:write"
+	PermissionUsersManage   Permission = "users:manage"
+)
+
+var permissions = []Permission{
+	PermissionProductsRead,
+	PermissionProductsWrite,
+	PermissionUsersManage,
+}
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin:  {PermissionProductsRead, PermissionProductsWrite, PermissionUsersManage},
+	RoleEditor: {PermissionProductsRead, PermissionProductsWrite},
+	RoleViewer: {PermissionProductsRead},
+}
+
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+func (r Role) HasPermission(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Permission) Valid() bool {
+	for _, known := range permissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package models
+
+import "strings"
+
+// A token's scope is the subset of permissions it may exercise, encoded as
+// a space-delimited list as in RFC 6749.
+
+func ParseScope(scope string) []Permission {
+	fields := strings.Fields(scope)
+	scopes := make([]Permission, 0, len(fields))
+	for _, field := range fields {
+		scopes = append(scopes, Permission(field))
+	}
+	return scopes
+}
+
+func FormatScope(scopes []Permission) string {
+	fields := make([]string, len(scopes))
+	for i, scope := range scopes {
+		fields[i] = string(scope)
+	}
+	return strings.Join(fields, " ")
+}
+
+// ScopeIncludes reports whether every permission in requested is in granted.
+func ScopeIncludes(granted, requested []Permission) bool {
+	for _, r := range requested {
+		found := false
+		for _, g := range granted {
+			if g == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
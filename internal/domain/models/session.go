@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Session is a single login on one device. It lasts as long as its refresh
+// tokens keep being rotated and ends when it is revoked or expires.
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	// Current marks the session the listing request was made from
+	Current bool `json:"current"`
+}
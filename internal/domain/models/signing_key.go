@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// SigningKey is an asymmetric key used to sign access tokens. The private key
+// is stored PKCS#8 PEM encoded and encrypted with AES-GCM, apart from keys
+// created before encryption was introduced. A key without ExpiresAt is the active signing
+// key; rotated keys stay available for verification until ExpiresAt.
+type SigningKey struct {
+	ID         string     `json:"kid"`
+	Algorithm  string     `json:"algorithm"`
+	PrivateKey string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import "github.com/prakoso-id/go-windsurf/internal/domain/models"
+
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	FindByID(id string) (*models.APIKey, error)
+	FindByHash(keyHash string) (*models.APIKey, error)
+	FindByUserID(userID string) ([]models.APIKey, error)
+	Revoke(id string) error
+	// TouchLastUsed records that the key was just used. Updates are
+	// throttled so busy keys do not cause a write on every request.
+	TouchLastUsed(id string) error
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import "github.com/prakoso-id/go-windsurf/internal/domain/models"
+
+type AuditEventRepository interface {
+	Create(event *models.AuditEvent) error
+	// FindByUserID returns the events about or caused by the user, oldest
+	// first.
+	FindByUserID(userID string) ([]models.AuditEvent, error)
+	// AnonymizeUser removes the IP addresses, email addresses and login
+	// throttle keys recorded in the user's events, keeping the events
+	// themselves.
+	AnonymizeUser(userID string) error
+}
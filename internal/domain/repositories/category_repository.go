@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+)
+
+// Writes fail with these errors when the database rejects them, which
+// happens when a concurrent request gets past the checks made beforehand.
+var (
+	// ErrCategoryNameConflict means a sibling already has the name.
+	ErrCategoryNameConflict = errors.New("category name conflicts with a sibling")
+	// ErrCategoryReferenced means the category still has subcategories or
+	// products.
+	ErrCategoryReferenced = errors.New("category is still referenced")
+	// ErrCategoryMissing means a parent or product category does not exist.
+	ErrCategoryMissing = errors.New("category does not exist")
+)
+
+type CategoryRepository interface {
+	// Create inserts the category under its parent, or at the top level if
+	// ParentID is nil, and fills in its ID, Path and Depth.
+	Create(category *models.Category) error
+	FindByID(id uint) (*models.Category, error)
+	// FindByIDs returns the categories with the given IDs that exist.
+	FindByIDs(ids []uint) ([]models.Category, error)
+	// FindAll returns every category, ordered by name.
+	FindAll() ([]models.Category, error)
+	// NameTaken reports whether a sibling other than excludeID already has
+	// the name, ignoring case.
+	NameTaken(parentID *uint, name string, excludeID uint) (bool, error)
+	// Update changes the category's name and description.
+	Update(category *models.Category) error
+	// Move puts the category, along with its descendants, under a new parent
+	// or at the top level if parentID is nil.
+	Move(id uint, parentID *uint) error
+	// InUse reports whether the category has subcategories or products.
+	InUse(id uint) (bool, error)
+	Delete(id uint) error
+	// FindProductCategories returns the category placements of the products.
+	FindProductCategories(productIDs []uint) ([]models.ProductCategory, error)
+}
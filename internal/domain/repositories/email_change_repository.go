@@ -0,0 +1,24 @@
+package repositories
+
+import "github.com/prakoso-id/go-windsurf/internal/domain/models"
+
+type EmailChangeRepository interface {
+	Create(change *models.EmailChange) error
+	// CancelPendingForUser deletes the user's unconfirmed changes.
+	CancelPendingForUser(userID string) error
+	// FindPendingByConfirmHash returns the pending, unexpired change with the
+	// confirmation token, or nil if none exists.
+	FindPendingByConfirmHash(confirmTokenHash string) (*models.EmailChange, error)
+	// FindRevertableByHash returns the change whose revert link has not been
+	// used or expired, or nil if none exists.
+	FindRevertableByHash(revertTokenHash string) (*models.EmailChange, error)
+	// Confirm marks a pending, unexpired change as confirmed and moves the
+	// user to the new address in the same transaction. It returns the change,
+	// or nil if no such change exists, and succeeds at most once.
+	Confirm(confirmTokenHash string) (*models.EmailChange, error)
+	// Revert marks a change whose revert link has not expired as reverted
+	// and, if it was confirmed, moves the user back to the old address in the
+	// same transaction. It returns the change, or nil if no such change
+	// exists, and succeeds at most once.
+	Revert(revertTokenHash string) (*models.EmailChange, error)
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+)
+
+type LoginThrottleRepository interface {
+	Find(key string) (*models.LoginThrottle, error)
+	// RecordFailure increments the failure count for the key and returns the
+	// updated throttle. Counts older than window start again from one.
+	RecordFailure(key string, window time.Duration) (*models.LoginThrottle, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import "github.com/prakoso-id/go-windsurf/internal/domain/models"
+
+type MFARepository interface {
+	// SaveTOTP stores a pending credential, replacing any earlier one.
+	SaveTOTP(credential *models.TOTPCredential) error
+	FindTOTP(userID string) (*models.TOTPCredential, error)
+	EnableTOTP(userID string) error
+	// AdvanceTOTPStep records step as used. It reports false when the same
+	// or a later step was already used, i.e. the code is being replayed.
+	AdvanceTOTPStep(userID string, step int64) (bool, error)
+	// DeleteTOTP removes the credential together with all recovery codes.
+	DeleteTOTP(userID string) error
+	ReplaceRecoveryCodes(userID string, codes []models.RecoveryCode) error
+	// UseRecoveryCode marks an unused code as used and reports whether one
+	// matched.
+	UseRecoveryCode(userID, codeHash string) (bool, error)
+}
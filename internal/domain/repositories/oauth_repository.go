@@ -0,0 +1,24 @@
+package repositories
+
+import "github.com/prakoso-id/go-windsurf/internal/domain/models"
+
+type OAuthClientRepository interface {
+	Create(client *models.OAuthClient) error
+	FindByID(id string) (*models.OAuthClient, error)
+	FindByOwnerID(ownerID string) ([]models.OAuthClient, error)
+	Delete(id string) error
+}
+
+type OAuthAuthorizationCodeRepository interface {
+	Create(code *models.OAuthAuthorizationCode) error
+	// Consume deletes and returns an unexpired code, or returns nil.
+	Consume(codeHash string) (*models.OAuthAuthorizationCode, error)
+}
+
+type OAuthConsentRepository interface {
+	Find(userID, clientID string) (*models.OAuthConsent, error)
+	FindByUserID(userID string) ([]models.OAuthConsent, error)
+	// Save stores the consent, replacing the scopes of an earlier one.
+	Save(consent *models.OAuthConsent) error
+	Delete(userID, clientID string) (bool, error)
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import "github.com/prakoso-id/go-windsurf/internal/domain/models"
+
+type OIDCLoginStateRepository interface {
+	Create(state *models.OIDCLoginState) error
+	// Consume deletes and returns an unexpired state, or returns nil.
+	Consume(state string) (*models.OIDCLoginState, error)
+}
+
+type UserIdentityRepository interface {
+	Create(identity *models.UserIdentity) error
+	FindByProviderSubject(provider, subject string) (*models.UserIdentity, error)
+	FindByUserID(userID string) ([]models.UserIdentity, error)
+}
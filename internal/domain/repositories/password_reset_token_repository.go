@@ -0,0 +1,15 @@
+package repositories
+
+import "github.com/prakoso-id/go-windsurf/internal/domain/models"
+
+type PasswordResetTokenRepository interface {
+	Create(token *models.PasswordResetToken) error
+	// FindValid returns an unused, unexpired token, or nil if no such token
+	// exists.
+	FindValid(tokenHash string) (*models.PasswordResetToken, error)
+	// Consume marks an unused, unexpired token as used and returns it, or
+	// returns nil if no such token exists. It succeeds at most once per token.
+	Consume(tokenHash string) (*models.PasswordResetToken, error)
+	// InvalidateForUser marks every outstanding token of the user as used.
+	InvalidateForUser(userID string) error
+}
@@ -0,0 +1,10 @@
+package repositories
+
+import "github.com/prakoso-id/go-windsurf/internal/domain/models"
+
+type PersonalAccessTokenRepository interface {
+	Create(token *models.PersonalAccessToken) error
+	FindByID(id string) (*models.PersonalAccessToken, error)
+	FindByUserID(userID string) ([]models.PersonalAccessToken, error)
+	Revoke(id string) error
+}
@@ -1,11 +1,80 @@
 package repositories
 
-import "github.com/prakoso-id/go-windsurf/internal/domain/models"
+import (
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+)
+
+// ProductSortField is a column products can be ordered by. Ties are broken
+// by ID, so that every product has a unique position.
+type ProductSortField string
+
+const (
+	ProductSortCreatedAt ProductSortField = "created_at"
+	ProductSortName      ProductSortField = "name"
+	ProductSortPrice     ProductSortField = "price"
+)
+
+// ProductCursor is the position of a product in a sorted list. SortValue
+// holds the product's value of the sort field: a time.Time, string or
+// float64.
+type ProductCursor struct {
+	SortValue interface{}
+	ID        uint
+}
+
+// ProductFilter selects a page of products for List. Zero values match every
+// product; the price and creation ranges include their lower bound and
+// exclude CreatedBefore.
+type ProductFilter struct {
+	NameContains  string
+	MinPrice      *float64
+	MaxPrice      *float64
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	SortField     ProductSortField
+	Descending    bool
+	// CategoryPath limits the list to products in the category at this path
+	// or in any category below it.
+	CategoryPath string
+	// After continues the list past the product at the cursor.
+	After *ProductCursor
+	Limit int
+}
+
+// ProductSearch selects a page of full-text search results. Query uses web
+// search syntax: quoted phrases, "or" and a leading "-" to exclude a word.
+type ProductSearch struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// ProductSuggestionQuery selects product names resembling Input, which may
+// be misspelled or cut short. Names scoring below MinScore are left out.
+type ProductSuggestionQuery struct {
+	Input    string
+	MinScore float64
+	Limit    int
+}
 
 type ProductRepository interface {
+	// Create inserts the product along with its primary and secondary
+	// categories.
 	Create(product *models.Product) error
 	FindByID(id uint) (*models.Product, error)
-	FindAll() ([]models.Product, error)
+	// List returns a page of products matching the filter, along with the
+	// number of products matching it regardless of the cursor.
+	List(filter ProductFilter) ([]models.Product, int, error)
+	FindByOwnerID(ownerID string) ([]models.Product, error)
+	// Search returns a page of products matching the query, most relevant
+	// first, along with the number of products matching it.
+	Search(search ProductSearch) ([]models.ProductSearchHit, int, error)
+	// SuggestNames returns distinct product names resembling the input,
+	// best match first.
+	SuggestNames(query ProductSuggestionQuery) ([]models.ProductSuggestion, error)
+	// Update replaces the product and its categories.
 	Update(product *models.Product) error
 	Delete(id uint) error
 }
@@ -0,0 +1,14 @@
+package repositories
+
+import "github.com/prakoso-id/go-windsurf/internal/domain/models"
+
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	FindByHash(tokenHash string) (*models.RefreshToken, error)
+	// Revoke marks the token as revoked, recording the token that replaced it
+	// (empty when it was not rotated). It reports false when the token had
+	// already been revoked, which lets callers detect concurrent reuse.
+	Revoke(id, replacedBy string) (bool, error)
+	RevokeFamily(familyID string) error
+	RevokeAllForUser(userID string) error
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+)
+
+type SessionRepository interface {
+	Create(session *models.Session) error
+	FindByID(id string) (*models.Session, error)
+	// FindActiveByUserID returns the user's sessions that are neither
+	// revoked nor expired, most recently used first.
+	FindActiveByUserID(userID string) ([]models.Session, error)
+	// FindByUserID returns all of the user's sessions, including revoked and
+	// expired ones, newest first.
+	FindByUserID(userID string) ([]models.Session, error)
+	// Touch records a use of the session from the given IP address and
+	// extends it until expiresAt.
+	Touch(id, ipAddress string, expiresAt time.Time) error
+	Revoke(id string) error
+	RevokeAllForUser(userID string) error
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+)
+
+type SigningKeyRepository interface {
+	Create(key *models.SigningKey) error
+	// FindValid returns every key that can still verify tokens, newest first.
+	FindValid() ([]models.SigningKey, error)
+	// RetireAllExcept schedules every other active key to expire at the given
+	// time, or later if a token signed with it is retained beyond that.
+	RetireAllExcept(kid string, expiresAt time.Time) error
+	// RetainUntil keeps the key verifiable at least until the given time.
+	RetainUntil(kid string, until time.Time) error
+	DeleteExpired() error
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+)
+
+type TokenDenylistRepository interface {
+	Add(token *models.RevokedToken) error
+	IsRevoked(jti string) (bool, error)
+	// RevokeAllForUser rejects every token issued to the user before the
+	// given time.
+	RevokeAllForUser(userID string, before time.Time) error
+	RevokedBefore(userID string) (*time.Time, error)
+	// RevokeAllForClient rejects every token issued to the OAuth client on
+	// behalf of the user before the given time, or on behalf of any user if
+	// userID is empty. It may be forgotten after expiresAt.
+	RevokeAllForClient(clientID, userID string, before, expiresAt time.Time) error
+	// ClientRevokedBefore returns the latest revocation covering tokens
+	// issued to the OAuth client on behalf of the user.
+	ClientRevokedBefore(clientID, userID string) (*time.Time, error)
+	DeleteExpired() error
+}
@@ -1,13 +1,48 @@
 package repositories
 
-import "github.com/prakoso-id/go-windsurf/internal/domain/models"
+import (
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+)
+
+// UserFilter selects a page of users for List. Search matches part of the
+// email address or name; zero values match every user.
+type UserFilter struct {
+	Search   string
+	Role     models.Role
+	Disabled *bool
+	Limit    int
+	Offset   int
+}
 
 type UserRepository interface {
 	Create(user *models.User) error
 	FindByID(id string) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
-	FindByEmailAndPassword(email, password string) (*models.User, error)
+	// List returns a page of users ordered by creation time, along with the
+	// number of users matching the filter.
+	List(filter UserFilter) ([]models.User, int, error)
 	Update(user *models.User) error
-	UpdatePassword(id string, newPassword string) error
+	UpdatePassword(id string, passwordHash string) error
+	UpdateRole(id string, role models.Role) error
+	MarkEmailVerified(id string) error
+	SetDisabled(id string, disabled bool) error
+	// ScheduleDeletion sets when the account will be deleted. A nil time
+	// cancels a scheduled deletion.
+	ScheduleDeletion(id string, at *time.Time) error
+	// FindDueForDeletion returns users whose scheduled deletion is at or
+	// before the given time.
+	FindDueForDeletion(before time.Time) ([]models.User, error)
+	// ClaimVerificationEmail records that a verification email is about to be
+	// sent. It returns false if one was already sent within the interval.
+	ClaimVerificationEmail(id string, interval time.Duration) (bool, error)
+	// ClaimMagicLinkEmail records that a login link is about to be sent. It
+	// returns false if one was already sent within the interval.
+	ClaimMagicLinkEmail(id string, interval time.Duration) (bool, error)
+	// ClaimPasswordResetEmail records that a requested password reset email
+	// is about to be sent. It returns false if one was already sent within
+	// the interval.
+	ClaimPasswordResetEmail(id string, interval time.Duration) (bool, error)
 	Delete(id string) error
 }
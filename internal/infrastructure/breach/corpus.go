@@ -0,0 +1,64 @@
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+)
+
+// prefixLength is the number of hex characters of the SHA-1 hash that name
+// a range file, as in the Have I Been Pwned range API.
+const prefixLength = 5
+
+type corpus struct {
+	dir string
+}
+
+// NewCorpus screens passwords against a local copy of the Have I Been Pwned
+// password corpus. The directory holds one file per hash prefix, named
+// <PREFIX>.txt, with SUFFIX:COUNT lines, which is the layout written by the
+// haveibeenpwned-downloader tool. Only the file for the password's prefix is
+// read, so the corpus is never loaded into memory.
+func NewCorpus(dir string) (services.BreachedPasswordChecker, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &corpus{dir: dir}, nil
+}
+
+func (c *corpus) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+
+	f, err := os.Open(filepath.Join(c.dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		// A partial corpus is allowed
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineSuffix, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		// Padding entries added by the range API have a count of 0
+		if ok && strings.EqualFold(lineSuffix, suffix) && count != "0" {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,29 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+)
+
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer writes every message to its own .eml file in dir instead of
+// sending it, for local development.
+func NewFileMailer(dir, from string) (services.Mailer, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &fileMailer{dir: dir, from: from}, nil
+}
+
+func (m *fileMailer) Send(msg *services.Message) error {
+	name := time.Now().Format("20060102T150405") + "-" + uuid.New().String() + ".eml"
+	return os.WriteFile(filepath.Join(m.dir, name), format(m.from, msg), 0o600)
+}
@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"sync"
+
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+)
+
+// MemoryMailer keeps sent messages in memory so that tests can inspect them.
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []services.Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(msg *services.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, *msg)
+	return nil
+}
+
+// Messages returns a copy of every message sent so far.
+func (m *MemoryMailer) Messages() []services.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]services.Message(nil), m.messages...)
+}
@@ -9,8 +9,25 @@ import (
 	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
 )
 
-func AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
+// AuthMiddleware accepts either an "Authorization: Bearer" access token or an
+// "X-API-Key" header. Both resolve to the same userID and claims context
+// values, so downstream handlers need not care which one was used.
+func AuthMiddleware(authService services.AuthService, apiKeyService services.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			claims, err := apiKeyService.Authenticate(apiKey)
+			if err != nil {
+				response.Error(c, http.StatusUnauthorized, "Invalid API key", err.Error())
+				c.Abort()
+				return
+			}
+
+			c.Set("userID", claims.UserID)
+			c.Set("claims", claims)
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			response.Error(c, http.StatusUnauthorized, "Authorization required", "missing authorization header")
@@ -26,15 +43,18 @@ func AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
 			return
 		}
 
-		userID, err := authService.ValidateToken(parts[1])
+		// Signature, expiry, the revocation denylist and whether the account
+		// is still enabled are all checked here
+		claims, err := authService.ValidateToken(parts[1])
 		if err != nil {
 			response.Error(c, http.StatusUnauthorized, "Invalid token", err.Error())
 			c.Abort()
 			return
 		}
 
-		// Store user ID in context
-		c.Set("userID", userID)
+		// Store user ID and the token claims in context
+		c.Set("userID", claims.UserID)
+		c.Set("claims", claims)
 		c.Next()
 	}
 }
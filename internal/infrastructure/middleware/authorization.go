@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
+)
+
+// RequireRole only lets the request through when the authenticated user has
+// one of the given roles. It must run after AuthMiddleware.
+func RequireRole(roles ...models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := accessClaims(c)
+		if !ok {
+			return
+		}
+
+		for _, role := range roles {
+			if claims.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		response.Error(c, http.StatusForbidden, "Forbidden", "insufficient role")
+		c.Abort()
+	}
+}
+
+// RequirePermission only lets the request through when the authenticated
+// user's role grants the permission. It must run after AuthMiddleware.
+func RequirePermission(permission models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := accessClaims(c)
+		if !ok {
+			return
+		}
+
+		if !claims.Role.HasPermission(permission) {
+			response.Error(c, http.StatusForbidden, "Forbidden", "missing permission "+string(permission))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireScope only lets the request through when the access token's scope
+// includes the given permission. Scopes are narrowed to the permissions of
+// the user's current role when the token is validated.
+func RequireScope(scope models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := accessClaims(c)
+		if !ok {
+			return
+		}
+
+		if !claims.HasScope(scope) {
+			response.Error(c, http.StatusForbidden, "Forbidden", "token is missing scope "+string(scope))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail only lets the request through when the user had
+// verified their email address when the token was issued.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := accessClaims(c)
+		if !ok {
+			return
+		}
+
+		if !claims.EmailVerified {
+			response.Error(c, http.StatusForbidden, "Forbidden", "email address is not verified")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// DenyClientTokens rejects tokens issued to third-party OAuth clients, which
+// may act within their scopes but must not manage the user's account.
+func DenyClientTokens() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := accessClaims(c)
+		if !ok {
+			return
+		}
+
+		if claims.ClientID != "" {
+			response.Error(c, http.StatusForbidden, "Forbidden", "not available to oauth clients")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// DenyImpersonation rejects impersonation tokens on routes that change the
+// user's credentials, so that an admin acting as a user cannot take over the
+// account or mint credentials that outlive the impersonation.
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := accessClaims(c)
+		if !ok {
+			return
+		}
+
+		if claims.ActorID != "" {
+			response.Error(c, http.StatusForbidden, "Forbidden", "not available while impersonating")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRecentAuth only lets the request through when the token was issued
+// by re-authenticating within maxAge, so that a stolen or unattended session
+// cannot be used to take over the account.
+func RequireRecentAuth(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := accessClaims(c)
+		if !ok {
+			return
+		}
+
+		if claims.AuthTime.IsZero() || time.Since(claims.AuthTime) > maxAge {
+			response.Error(c, http.StatusForbidden, "Reauthentication required", "re-enter your credentials at /auth/reauthenticate and retry with the token it returns")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// accessClaims returns the claims stored by AuthMiddleware, aborting the
+// request when there are none.
+func accessClaims(c *gin.Context) (*services.AccessClaims, bool) {
+	value, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		c.Abort()
+		return nil, false
+	}
+	return value.(*services.AccessClaims), true
+}
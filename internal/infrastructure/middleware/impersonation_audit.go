@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+)
+
+// ImpersonatedByHeader names the admin on every response to a request made
+// with an impersonation token, so that clients can show a banner.
+const ImpersonatedByHeader = "X-Impersonated-By"
+
+// AuditImpersonation records every request made with an impersonation token
+// as an audit event. It must run after AuthMiddleware.
+func AuditImpersonation(auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Next()
+			return
+		}
+		accessClaims := claims.(*services.AccessClaims)
+		if accessClaims.ActorID == "" {
+			c.Next()
+			return
+		}
+
+		c.Header(ImpersonatedByHeader, accessClaims.ActorID)
+		c.Next()
+
+		err := auditService.Record(&models.AuditEvent{
+			Type:      models.AuditImpersonatedRequest,
+			UserID:    &accessClaims.UserID,
+			ActorID:   &accessClaims.ActorID,
+			IPAddress: c.ClientIP(),
+			Details: map[string]string{
+				"token_id": accessClaims.TokenID,
+				"method":   c.Request.Method,
+				"path":     c.Request.URL.Path,
+				"status":   strconv.Itoa(c.Writer.Status()),
+			},
+		})
+		if err != nil {
+			log.Println("Error recording impersonated request:", err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+type auditEventRepository struct {
+	db *sql.DB
+}
+
+func NewAuditEventRepository(db *sql.DB) repositories.AuditEventRepository {
+	return &auditEventRepository{db: db}
+}
+
+func (r *auditEventRepository) Create(event *models.AuditEvent) error {
+	details, err := json.Marshal(event.Details)
+	if err != nil {
+		return err
+	}
+	if event.Details == nil {
+		details = []byte("{}")
+	}
+
+	query := `
+		INSERT INTO audit_events (id, event_type, user_id, actor_id, ip_address, details, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+	`
+	_, err = r.db.Exec(
+		query,
+		event.ID,
+		event.Type,
+		event.UserID,
+		event.ActorID,
+		event.IPAddress,
+		string(details),
+		event.CreatedAt,
+	)
+	return err
+}
+
+func (r *auditEventRepository) FindByUserID(userID string) ([]models.AuditEvent, error) {
+	query := `
+		SELECT id, event_type, user_id, actor_id, COALESCE(ip_address, ''), details, created_at
+		FROM audit_events
+		WHERE user_id = $1 OR actor_id = $1
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		var event models.AuditEvent
+		var details []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.UserID,
+			&event.ActorID,
+			&event.IPAddress,
+			&details,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(details, &event.Details); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (r *auditEventRepository) AnonymizeUser(userID string) error {
+	query := `
+		UPDATE audit_events
+		SET ip_address = NULL, details = details - ARRAY['email', 'old_email', 'new_email', 'key']
+		WHERE user_id = $1 OR actor_id = $1
+	`
+	_, err := r.db.Exec(query, userID)
+	return err
+}
@@ -0,0 +1,191 @@
+package persistence
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+// revokedEntryTTL bounds how long a positive lookup loaded from the database
+// is kept. Revocations are permanent, so this only limits memory use.
+const revokedEntryTTL = time.Hour
+
+type denylistEntry struct {
+	revoked bool
+	until   time.Time
+}
+
+type revocationEntry struct {
+	before *time.Time
+	until  time.Time
+}
+
+// cachedTokenDenylistRepository keeps denylist lookups in memory so that the
+// auth middleware does not query Postgres on every request. Revocations made
+// through this instance are visible immediately; revocations made by other
+// instances are picked up once the cached negative answer expires.
+type cachedTokenDenylistRepository struct {
+	repo repositories.TokenDenylistRepository
+	ttl  time.Duration
+
+	mu          sync.RWMutex
+	tokens      map[string]denylistEntry
+	revocations map[string]revocationEntry
+	// clientRevocations is keyed by client ID and user ID
+	clientRevocations map[[2]string]revocationEntry
+}
+
+func NewCachedTokenDenylistRepository(repo repositories.TokenDenylistRepository, ttl time.Duration) repositories.TokenDenylistRepository {
+	r := &cachedTokenDenylistRepository{
+		repo:        repo,
+		ttl:         ttl,
+		tokens:      make(map[string]denylistEntry),
+		revocations: make(map[string]revocationEntry),
+
+		clientRevocations: make(map[[2]string]revocationEntry),
+	}
+	go r.cleanup()
+	return r
+}
+
+func (r *cachedTokenDenylistRepository) Add(token *models.RevokedToken) error {
+	if err := r.repo.Add(token); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.tokens[token.JTI] = denylistEntry{revoked: true, until: token.ExpiresAt}
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *cachedTokenDenylistRepository) IsRevoked(jti string) (bool, error) {
+	r.mu.RLock()
+	entry, ok := r.tokens[jti]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(entry.until) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := r.repo.IsRevoked(jti)
+	if err != nil {
+		return false, err
+	}
+
+	until := time.Now().Add(r.ttl)
+	if revoked {
+		until = time.Now().Add(revokedEntryTTL)
+	}
+	r.mu.Lock()
+	r.tokens[jti] = denylistEntry{revoked: revoked, until: until}
+	r.mu.Unlock()
+	return revoked, nil
+}
+
+func (r *cachedTokenDenylistRepository) RevokeAllForUser(userID string, before time.Time) error {
+	if err := r.repo.RevokeAllForUser(userID, before); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.revocations[userID] = revocationEntry{before: &before, until: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *cachedTokenDenylistRepository) RevokedBefore(userID string) (*time.Time, error) {
+	r.mu.RLock()
+	entry, ok := r.revocations[userID]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(entry.until) {
+		return entry.before, nil
+	}
+
+	before, err := r.repo.RevokedBefore(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.revocations[userID] = revocationEntry{before: before, until: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+	return before, nil
+}
+
+func (r *cachedTokenDenylistRepository) RevokeAllForClient(clientID, userID string, before, expiresAt time.Time) error {
+	if err := r.repo.RevokeAllForClient(clientID, userID, before, expiresAt); err != nil {
+		return err
+	}
+
+	// A revocation for every user applies to each cached user of the client
+	r.mu.Lock()
+	for key := range r.clientRevocations {
+		if key[0] == clientID && (userID == "" || key[1] == userID) {
+			delete(r.clientRevocations, key)
+		}
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *cachedTokenDenylistRepository) ClientRevokedBefore(clientID, userID string) (*time.Time, error) {
+	key := [2]string{clientID, userID}
+	r.mu.RLock()
+	entry, ok := r.clientRevocations[key]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(entry.until) {
+		return entry.before, nil
+	}
+
+	before, err := r.repo.ClientRevokedBefore(clientID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.clientRevocations[key] = revocationEntry{before: before, until: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+	return before, nil
+}
+
+func (r *cachedTokenDenylistRepository) DeleteExpired() error {
+	return r.repo.DeleteExpired()
+}
+
+func (r *cachedTokenDenylistRepository) cleanup() {
+	prune := time.NewTicker(time.Hour)
+	defer prune.Stop()
+	evict := time.NewTicker(time.Minute)
+	defer evict.Stop()
+
+	for {
+		select {
+		case <-evict.C:
+			now := time.Now()
+			r.mu.Lock()
+			for jti, entry := range r.tokens {
+				if now.After(entry.until) {
+					delete(r.tokens, jti)
+				}
+			}
+			for userID, entry := range r.revocations {
+				if now.After(entry.until) {
+					delete(r.revocations, userID)
+				}
+			}
+			for key, entry := range r.clientRevocations {
+				if now.After(entry.until) {
+					delete(r.clientRevocations, key)
+				}
+			}
+			r.mu.Unlock()
+		case <-prune.C:
+			if err := r.repo.DeleteExpired(); err != nil {
+				log.Println("Error pruning expired revoked tokens:", err)
+			}
+		}
+	}
+}
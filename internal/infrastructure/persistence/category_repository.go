@@ -0,0 +1,309 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const categoryColumns = `id, parent_id, name, description, path, depth, created_at, updated_at`
+
+type categoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) repositories.CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func scanCategory(row rowScanner) (*models.Category, error) {
+	var category models.Category
+	err := row.Scan(
+		&category.ID,
+		&category.ParentID,
+		&category.Name,
+		&category.Description,
+		&category.Path,
+		&category.Depth,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) Create(category *models.Category) error {
+	// The path ends with the category's own ID, so the ID is taken from the
+	// sequence before the row is inserted
+	query := `
+		INSERT INTO categories (id, parent_id, name, description, path, depth, created_at, updated_at)
+		SELECT seq.id, $1, $2, $3, COALESCE(parent.path, '/') || seq.id || '/', COALESCE(parent.depth + 1, 0), $4, $4
+		FROM (SELECT nextval(pg_get_serial_sequence('categories', 'id')) AS id) seq
+		LEFT JOIN categories parent ON parent.id = $1
+		RETURNING id, path, depth
+	`
+	now := time.Now()
+	err := r.db.QueryRow(
+		query,
+		category.ParentID,
+		category.Name,
+		category.Description,
+		now,
+	).Scan(&category.ID, &category.Path, &category.Depth)
+	if err != nil {
+		return categoryWriteError(err)
+	}
+
+	category.CreatedAt = now
+	category.UpdatedAt = now
+	return nil
+}
+
+func (r *categoryRepository) FindByID(id uint) (*models.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE id = $1
+	`
+	category, err := scanCategory(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (r *categoryRepository) FindByIDs(ids []uint) ([]models.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE id = ANY($1)
+		ORDER BY path
+	`
+	return r.query(query, pq.Array(int64IDs(ids)))
+}
+
+func (r *categoryRepository) FindAll() ([]models.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		ORDER BY name, id
+	`
+	return r.query(query)
+}
+
+func (r *categoryRepository) query(query string, args ...interface{}) ([]models.Category, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *category)
+	}
+	return categories, rows.Err()
+}
+
+func (r *categoryRepository) NameTaken(parentID *uint, name string, excludeID uint) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM categories
+			WHERE COALESCE(parent_id, 0) = COALESCE($1, 0) AND LOWER(name) = LOWER($2) AND id <> $3
+		)
+	`
+	var taken bool
+	err := r.db.QueryRow(query, parentID, name, excludeID).Scan(&taken)
+	return taken, err
+}
+
+func (r *categoryRepository) Update(category *models.Category) error {
+	query := `
+		UPDATE categories
+		SET name = $1, description = $2, updated_at = $3
+		WHERE id = $4
+	`
+	result, err := r.db.Exec(
+		query,
+		category.Name,
+		category.Description,
+		time.Now(),
+		category.ID,
+	)
+	if err != nil {
+		return categoryWriteError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("category not found")
+	}
+
+	return nil
+}
+
+func (r *categoryRepository) Move(id uint, parentID *uint) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldPath string
+	var oldDepth int
+	err = tx.QueryRow(`SELECT path, depth FROM categories WHERE id = $1 FOR UPDATE`, id).Scan(&oldPath, &oldDepth)
+	if err == sql.ErrNoRows {
+		return errors.New("category not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	newPrefix, newDepth := "/", 0
+	if parentID != nil {
+		var parentPath string
+		var parentDepth int
+		err = tx.QueryRow(`SELECT path, depth FROM categories WHERE id = $1 FOR UPDATE`, *parentID).Scan(&parentPath, &parentDepth)
+		if err == sql.ErrNoRows {
+			return repositories.ErrCategoryMissing
+		}
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(parentPath, oldPath) {
+			return errors.New("category cannot be moved below itself")
+		}
+		newPrefix, newDepth = parentPath, parentDepth+1
+	}
+
+	// Every path in the subtree swaps the old parent's prefix for the new one
+	oldPrefix := strings.TrimSuffix(oldPath, categoryPathSegment(id))
+	query := `
+		UPDATE categories
+		SET path = $1 || SUBSTRING(path FROM $2), depth = depth + $3, updated_at = $4
+		WHERE path LIKE $5
+	`
+	_, err = tx.Exec(query, newPrefix, len(oldPrefix)+1, newDepth-oldDepth, time.Now(), oldPath+"%")
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE categories SET parent_id = $1 WHERE id = $2`, parentID, id); err != nil {
+		return categoryWriteError(err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *categoryRepository) InUse(id uint) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)
+			OR EXISTS (SELECT 1 FROM product_categories WHERE category_id = $1)
+	`
+	var inUse bool
+	err := r.db.QueryRow(query, id).Scan(&inUse)
+	return inUse, err
+}
+
+func (r *categoryRepository) Delete(id uint) error {
+	query := `DELETE FROM categories WHERE id = $1`
+	result, err := r.db.Exec(query, id)
+	if violates(err, "foreign_key_violation") {
+		return repositories.ErrCategoryReferenced
+	}
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("category not found")
+	}
+
+	return nil
+}
+
+func (r *categoryRepository) FindProductCategories(productIDs []uint) ([]models.ProductCategory, error) {
+	query := `
+		SELECT pc.product_id, pc.category_id, pc.is_primary, c.path
+		FROM product_categories pc
+		JOIN categories c ON c.id = pc.category_id
+		WHERE pc.product_id = ANY($1)
+		ORDER BY pc.product_id, pc.is_primary DESC, pc.category_id
+	`
+	rows, err := r.db.Query(query, pq.Array(int64IDs(productIDs)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	placements := []models.ProductCategory{}
+	for rows.Next() {
+		var placement models.ProductCategory
+		err := rows.Scan(
+			&placement.ProductID,
+			&placement.CategoryID,
+			&placement.Primary,
+			&placement.Path,
+		)
+		if err != nil {
+			return nil, err
+		}
+		placements = append(placements, placement)
+	}
+	return placements, rows.Err()
+}
+
+// categoryWriteError translates the constraint violations of an insert or
+// update involving categories.
+func categoryWriteError(err error) error {
+	switch {
+	case violates(err, "unique_violation"):
+		return repositories.ErrCategoryNameConflict
+	case violates(err, "foreign_key_violation"):
+		return repositories.ErrCategoryMissing
+	}
+	return err
+}
+
+// violates reports whether err is a constraint violation of the named kind,
+// such as "unique_violation".
+func violates(err error, name string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == name
+}
+
+// categoryPathSegment is the part a category adds to its parent's path.
+func categoryPathSegment(id uint) string {
+	return strconv.FormatUint(uint64(id), 10) + "/"
+}
+
+// int64IDs converts IDs for use with pq.Array, which has no []uint support.
+func int64IDs(ids []uint) []int64 {
+	converted := make([]int64, len(ids))
+	for i, id := range ids {
+		converted[i] = int64(id)
+	}
+	return converted
+}
@@ -0,0 +1,182 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const emailChangeColumns = `id, user_id, old_email, new_email, confirm_token_hash, revert_token_hash, expires_at, revert_expires_at, created_at, confirmed_at, reverted_at`
+
+type emailChangeRepository struct {
+	db *sql.DB
+}
+
+func NewEmailChangeRepository(db *sql.DB) repositories.EmailChangeRepository {
+	return &emailChangeRepository{db: db}
+}
+
+func scanEmailChange(row rowScanner) (*models.EmailChange, error) {
+	var change models.EmailChange
+	err := row.Scan(
+		&change.ID,
+		&change.UserID,
+		&change.OldEmail,
+		&change.NewEmail,
+		&change.ConfirmTokenHash,
+		&change.RevertTokenHash,
+		&change.ExpiresAt,
+		&change.RevertExpiresAt,
+		&change.CreatedAt,
+		&change.ConfirmedAt,
+		&change.RevertedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *emailChangeRepository) Create(change *models.EmailChange) error {
+	query := `
+		INSERT INTO email_changes (id, user_id, old_email, new_email, confirm_token_hash, revert_token_hash, expires_at, revert_expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.Exec(
+		query,
+		change.ID,
+		change.UserID,
+		change.OldEmail,
+		change.NewEmail,
+		change.ConfirmTokenHash,
+		change.RevertTokenHash,
+		change.ExpiresAt,
+		change.RevertExpiresAt,
+		change.CreatedAt,
+	)
+	return err
+}
+
+func (r *emailChangeRepository) CancelPendingForUser(userID string) error {
+	query := `DELETE FROM email_changes WHERE user_id = $1 AND confirmed_at IS NULL AND reverted_at IS NULL`
+	_, err := r.db.Exec(query, userID)
+	return err
+}
+
+func (r *emailChangeRepository) FindPendingByConfirmHash(confirmTokenHash string) (*models.EmailChange, error) {
+	query := `
+		SELECT ` + emailChangeColumns + `
+		FROM email_changes
+		WHERE confirm_token_hash = $1 AND confirmed_at IS NULL AND reverted_at IS NULL AND expires_at > NOW()`
+	change, err := scanEmailChange(r.db.QueryRow(query, confirmTokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (r *emailChangeRepository) FindRevertableByHash(revertTokenHash string) (*models.EmailChange, error) {
+	query := `
+		SELECT ` + emailChangeColumns + `
+		FROM email_changes
+		WHERE revert_token_hash = $1 AND reverted_at IS NULL AND revert_expires_at > NOW()`
+	change, err := scanEmailChange(r.db.QueryRow(query, revertTokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (r *emailChangeRepository) Confirm(confirmTokenHash string) (*models.EmailChange, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE email_changes
+		SET confirmed_at = NOW()
+		WHERE confirm_token_hash = $1 AND confirmed_at IS NULL AND reverted_at IS NULL AND expires_at > NOW()
+		RETURNING ` + emailChangeColumns
+	change, err := scanEmailChange(tx.QueryRow(query, confirmTokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := switchEmail(tx, change.UserID, change.OldEmail, change.NewEmail); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (r *emailChangeRepository) Revert(revertTokenHash string) (*models.EmailChange, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE email_changes
+		SET reverted_at = NOW()
+		WHERE revert_token_hash = $1 AND reverted_at IS NULL AND revert_expires_at > NOW()
+		RETURNING ` + emailChangeColumns
+	change, err := scanEmailChange(tx.QueryRow(query, revertTokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if change.ConfirmedAt != nil {
+		if err := switchEmail(tx, change.UserID, change.NewEmail, change.OldEmail); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// switchEmail moves the user from one address to the other, failing if the
+// user no longer has the address the change started from.
+func switchEmail(tx *sql.Tx, userID, from, to string) error {
+	query := `
+		UPDATE users
+		SET email = $1, email_verified_at = NOW(), updated_at = NOW()
+		WHERE id = $2 AND email = $3
+	`
+	result, err := tx.Exec(query, to, userID, from)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("user email has changed")
+	}
+
+	return nil
+}
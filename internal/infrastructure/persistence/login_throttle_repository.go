@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+type loginThrottleRepository struct {
+	db *sql.DB
+}
+
+func NewLoginThrottleRepository(db *sql.DB) repositories.LoginThrottleRepository {
+	return &loginThrottleRepository{db: db}
+}
+
+func (r *loginThrottleRepository) Find(key string) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	query := `SELECT key, failures, last_failure_at, locked_until FROM login_throttles WHERE key = $1`
+	err := r.db.QueryRow(query, key).Scan(
+		&throttle.Key,
+		&throttle.Failures,
+		&throttle.LastFailureAt,
+		&throttle.LockedUntil,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+func (r *loginThrottleRepository) RecordFailure(key string, window time.Duration) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	query := `
+		INSERT INTO login_throttles (key, failures, last_failure_at)
+		VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+				WHEN login_throttles.last_failure_at < NOW() - make_interval(secs => $2) THEN 1
+				ELSE login_throttles.failures + 1
+			END,
+			last_failure_at = NOW()
+		RETURNING key, failures, last_failure_at, locked_until
+	`
+	err := r.db.QueryRow(query, key, window.Seconds()).Scan(
+		&throttle.Key,
+		&throttle.Failures,
+		&throttle.LastFailureAt,
+		&throttle.LockedUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+func (r *loginThrottleRepository) Lock(key string, until time.Time) error {
+	query := `UPDATE login_throttles SET locked_until = $1 WHERE key = $2`
+	_, err := r.db.Exec(query, until, key)
+	return err
+}
+
+func (r *loginThrottleRepository) Reset(key string) error {
+	query := `DELETE FROM login_throttles WHERE key = $1`
+	_, err := r.db.Exec(query, key)
+	return err
+}
@@ -0,0 +1,146 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+type mfaRepository struct {
+	db *sql.DB
+}
+
+func NewMFARepository(db *sql.DB) repositories.MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) SaveTOTP(credential *models.TOTPCredential) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, enabled_at = NULL, last_used_step = 0, created_at = EXCLUDED.created_at
+	`
+	_, err := r.db.Exec(query, credential.UserID, credential.Secret, credential.CreatedAt)
+	return err
+}
+
+func (r *mfaRepository) FindTOTP(userID string) (*models.TOTPCredential, error) {
+	var credential models.TOTPCredential
+	query := `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at
+		FROM user_totp
+		WHERE user_id = $1
+	`
+	err := r.db.QueryRow(query, userID).Scan(
+		&credential.UserID,
+		&credential.Secret,
+		&credential.EnabledAt,
+		&credential.LastUsedStep,
+		&credential.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *mfaRepository) EnableTOTP(userID string) error {
+	query := `UPDATE user_totp SET enabled_at = NOW() WHERE user_id = $1`
+	result, err := r.db.Exec(query, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("two-factor authentication is not enrolled")
+	}
+
+	return nil
+}
+
+func (r *mfaRepository) AdvanceTOTPStep(userID string, step int64) (bool, error) {
+	query := `
+		UPDATE user_totp
+		SET last_used_step = $1
+		WHERE user_id = $2 AND last_used_step < $1
+	`
+	result, err := r.db.Exec(query, step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *mfaRepository) DeleteTOTP(userID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(userID string, codes []models.RecoveryCode) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO mfa_recovery_codes (id, user_id, code_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, code := range codes {
+		if _, err := tx.Exec(query, code.ID, userID, code.CodeHash, code.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *mfaRepository) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	result, err := r.db.Exec(query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,237 @@
+package persistence
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+const oauthClientColumns = `id, owner_id, name, COALESCE(secret_hash, ''), redirect_uris, scopes, created_at`
+
+type oauthClientRepository struct {
+	db *sql.DB
+}
+
+func NewOAuthClientRepository(db *sql.DB) repositories.OAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+func scanOAuthClient(row rowScanner) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	var redirectURIs, scopes string
+	err := row.Scan(
+		&client.ID,
+		&client.OwnerID,
+		&client.Name,
+		&client.SecretHash,
+		&redirectURIs,
+		&scopes,
+		&client.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// Redirect URIs cannot contain spaces, so they share the scope encoding
+	client.RedirectURIs = strings.Fields(redirectURIs)
+	client.Scopes = models.ParseScope(scopes)
+	return &client, nil
+}
+
+func (r *oauthClientRepository) Create(client *models.OAuthClient) error {
+	query := `
+		INSERT INTO oauth_clients (id, owner_id, name, secret_hash, redirect_uris, scopes, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7)
+	`
+	_, err := r.db.Exec(
+		query,
+		client.ID,
+		client.OwnerID,
+		client.Name,
+		client.SecretHash,
+		strings.Join(client.RedirectURIs, " "),
+		models.FormatScope(client.Scopes),
+		client.CreatedAt,
+	)
+	return err
+}
+
+func (r *oauthClientRepository) FindByID(id string) (*models.OAuthClient, error) {
+	query := `SELECT ` + oauthClientColumns + ` FROM oauth_clients WHERE id = $1`
+	client, err := scanOAuthClient(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (r *oauthClientRepository) FindByOwnerID(ownerID string) ([]models.OAuthClient, error) {
+	query := `
+		SELECT ` + oauthClientColumns + `
+		FROM oauth_clients
+		WHERE owner_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []models.OAuthClient{}
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, *client)
+	}
+	return clients, rows.Err()
+}
+
+func (r *oauthClientRepository) Delete(id string) error {
+	_, err := r.db.Exec(`DELETE FROM oauth_clients WHERE id = $1`, id)
+	return err
+}
+
+type oauthAuthorizationCodeRepository struct {
+	db *sql.DB
+}
+
+func NewOAuthAuthorizationCodeRepository(db *sql.DB) repositories.OAuthAuthorizationCodeRepository {
+	return &oauthAuthorizationCodeRepository{db: db}
+}
+
+func (r *oauthAuthorizationCodeRepository) Create(code *models.OAuthAuthorizationCode) error {
+	// Unredeemed codes are cleaned up as new ones are issued
+	if _, err := r.db.Exec(`DELETE FROM oauth_authorization_codes WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.Exec(
+		query,
+		code.CodeHash,
+		code.ClientID,
+		code.UserID,
+		code.RedirectURI,
+		models.FormatScope(code.Scopes),
+		code.CodeChallenge,
+		code.ExpiresAt,
+		code.CreatedAt,
+	)
+	return err
+}
+
+func (r *oauthAuthorizationCodeRepository) Consume(codeHash string) (*models.OAuthAuthorizationCode, error) {
+	var code models.OAuthAuthorizationCode
+	var scopes string
+	query := `
+		DELETE FROM oauth_authorization_codes
+		WHERE code_hash = $1 AND expires_at > NOW()
+		RETURNING code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at, created_at
+	`
+	err := r.db.QueryRow(query, codeHash).Scan(
+		&code.CodeHash,
+		&code.ClientID,
+		&code.UserID,
+		&code.RedirectURI,
+		&scopes,
+		&code.CodeChallenge,
+		&code.ExpiresAt,
+		&code.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	code.Scopes = models.ParseScope(scopes)
+	return &code, nil
+}
+
+type oauthConsentRepository struct {
+	db *sql.DB
+}
+
+func NewOAuthConsentRepository(db *sql.DB) repositories.OAuthConsentRepository {
+	return &oauthConsentRepository{db: db}
+}
+
+func scanOAuthConsent(row rowScanner) (*models.OAuthConsent, error) {
+	var consent models.OAuthConsent
+	var scopes string
+	if err := row.Scan(&consent.UserID, &consent.ClientID, &scopes, &consent.GrantedAt); err != nil {
+		return nil, err
+	}
+	consent.Scopes = models.ParseScope(scopes)
+	return &consent, nil
+}
+
+func (r *oauthConsentRepository) Find(userID, clientID string) (*models.OAuthConsent, error) {
+	query := `SELECT user_id, client_id, scopes, granted_at FROM oauth_consents WHERE user_id = $1 AND client_id = $2`
+	consent, err := scanOAuthConsent(r.db.QueryRow(query, userID, clientID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return consent, nil
+}
+
+func (r *oauthConsentRepository) FindByUserID(userID string) ([]models.OAuthConsent, error) {
+	query := `
+		SELECT user_id, client_id, scopes, granted_at
+		FROM oauth_consents
+		WHERE user_id = $1
+		ORDER BY granted_at DESC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consents := []models.OAuthConsent{}
+	for rows.Next() {
+		consent, err := scanOAuthConsent(rows)
+		if err != nil {
+			return nil, err
+		}
+		consents = append(consents, *consent)
+	}
+	return consents, rows.Err()
+}
+
+func (r *oauthConsentRepository) Save(consent *models.OAuthConsent) error {
+	query := `
+		INSERT INTO oauth_consents (user_id, client_id, scopes, granted_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, client_id) DO UPDATE
+		SET scopes = EXCLUDED.scopes, granted_at = EXCLUDED.granted_at
+	`
+	_, err := r.db.Exec(query, consent.UserID, consent.ClientID, models.FormatScope(consent.Scopes), consent.GrantedAt)
+	return err
+}
+
+func (r *oauthConsentRepository) Delete(userID, clientID string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM oauth_consents WHERE user_id = $1 AND client_id = $2`, userID, clientID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
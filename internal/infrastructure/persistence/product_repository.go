@@ -3,12 +3,28 @@ package persistence
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prakoso-id/go-windsurf/internal/domain/models"
 	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
 )
 
+const productColumns = `id, name, description, price, owner_id, created_at, updated_at`
+
+// Headlines mark matches with control characters rather than HTML, so that
+// the text can be escaped before the <mark> tags are put in.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+
+	nameHeadlineOptions        = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", HighlightAll=true`
+	descriptionHeadlineOptions = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", MaxFragments=2, MaxWords=20, MinWords=5`
+)
+
 type productRepository struct {
 	db *sql.DB
 }
@@ -17,84 +33,328 @@ func NewProductRepository(db *sql.DB) repositories.ProductRepository {
 	return &productRepository{db: db}
 }
 
+func scanProduct(row rowScanner) (*models.Product, error) {
+	var product models.Product
+	err := row.Scan(
+		&product.ID,
+		&product.Name,
+		&product.Description,
+		&product.Price,
+		&product.OwnerID,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
 func (r *productRepository) Create(product *models.Product) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO products (name, description, price, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO products (name, description, price, owner_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
 	`
-	return r.db.QueryRow(
+	err = tx.QueryRow(
 		query,
 		product.Name,
 		product.Description,
 		product.Price,
+		product.OwnerID,
 		time.Now(),
 		time.Now(),
 	).Scan(&product.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := setProductCategories(tx, product); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *productRepository) FindByID(id uint) (*models.Product, error) {
-	var product models.Product
 	query := `
-		SELECT id, name, description, price, created_at, updated_at
+		SELECT ` + productColumns + `
 		FROM products
 		WHERE id = $1
 	`
-	err := r.db.QueryRow(query, id).Scan(
-		&product.ID,
-		&product.Name,
-		&product.Description,
-		&product.Price,
-		&product.CreatedAt,
-		&product.UpdatedAt,
-	)
+	product, err := scanProduct(r.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	return &product, nil
+	return product, nil
 }
 
-func (r *productRepository) FindAll() ([]models.Product, error) {
+// productSortColumns whitelists the columns a product list can be ordered by.
+var productSortColumns = map[repositories.ProductSortField]string{
+	repositories.ProductSortCreatedAt: "created_at",
+	repositories.ProductSortName:      "name",
+	repositories.ProductSortPrice:     "price",
+}
+
+func (r *productRepository) List(filter repositories.ProductFilter) ([]models.Product, int, error) {
+	sortColumn, ok := productSortColumns[filter.SortField]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported sort field %q", filter.SortField)
+	}
+
+	var conditions []string
+	var args []interface{}
+	if filter.NameContains != "" {
+		args = append(args, "%"+escapeLike(filter.NameContains)+"%")
+		conditions = append(conditions, fmt.Sprintf("name ILIKE $%d", len(args)))
+	}
+	if filter.MinPrice != nil {
+		args = append(args, *filter.MinPrice)
+		conditions = append(conditions, fmt.Sprintf("price >= $%d", len(args)))
+	}
+	if filter.MaxPrice != nil {
+		args = append(args, *filter.MaxPrice)
+		conditions = append(conditions, fmt.Sprintf("price <= $%d", len(args)))
+	}
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	if filter.CategoryPath != "" {
+		args = append(args, filter.CategoryPath+"%")
+		conditions = append(conditions, fmt.Sprintf(`id IN (
+			SELECT pc.product_id
+			FROM product_categories pc
+			JOIN categories c ON c.id = pc.category_id
+			WHERE c.path LIKE $%d
+		)`, len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM products `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	// Keyset pagination: continue after the cursor's (sort value, id) pair
+	if filter.After != nil {
+		args = append(args, filter.After.SortValue, filter.After.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)", sortColumn, comparison, len(args)-1, len(args)))
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT `+productColumns+`
+		FROM products
+		%s
+		ORDER BY %s %s, id %s
+		LIMIT $%d
+	`, where, sortColumn, direction, direction, len(args)+1)
+	rows, err := r.db.Query(query, append(args, filter.Limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	products := []models.Product{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		products = append(products, *product)
+	}
+	return products, total, rows.Err()
+}
+
+func (r *productRepository) FindByOwnerID(ownerID string) ([]models.Product, error) {
 	query := `
-		SELECT id, name, description, price, created_at, updated_at
+		SELECT ` + productColumns + `
 		FROM products
+		WHERE owner_id = $1
 		ORDER BY created_at DESC
 	`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, ownerID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var products []models.Product
+	products := []models.Product{}
 	for rows.Next() {
-		var product models.Product
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, *product)
+	}
+	return products, rows.Err()
+}
+
+func (r *productRepository) Search(search repositories.ProductSearch) ([]models.ProductSearchHit, int, error) {
+	var total int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM products
+		WHERE search_vector @@ websearch_to_tsquery('english', $1)
+	`
+	if err := r.db.QueryRow(countQuery, search.Query).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Headlines are costly, so they are only made for the page of hits. Any
+	// markers already in the text are removed first
+	query := `
+		SELECT p.id, p.name, p.description, p.price, p.owner_id, p.created_at, p.updated_at, hits.rank,
+			ts_headline('english', translate(p.name, $6, ''), hits.query, $4),
+			ts_headline('english', translate(COALESCE(p.description, ''), $6, ''), hits.query, $5)
+		FROM (
+			SELECT id, ts_rank_cd(search_vector, query) AS rank, query
+			FROM products, websearch_to_tsquery('english', $1) query
+			WHERE search_vector @@ query
+			ORDER BY rank DESC, id
+			LIMIT $2 OFFSET $3
+		) hits
+		JOIN products p ON p.id = hits.id
+		ORDER BY hits.rank DESC, p.id
+	`
+	rows, err := r.db.Query(query, search.Query, search.Limit, search.Offset, nameHeadlineOptions, descriptionHeadlineOptions, highlightStart+highlightStop)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	hits := []models.ProductSearchHit{}
+	for rows.Next() {
+		var hit models.ProductSearchHit
 		err := rows.Scan(
-			&product.ID,
-			&product.Name,
-			&product.Description,
-			&product.Price,
-			&product.CreatedAt,
-			&product.UpdatedAt,
+			&hit.ID,
+			&hit.Name,
+			&hit.Description,
+			&hit.Price,
+			&hit.OwnerID,
+			&hit.CreatedAt,
+			&hit.UpdatedAt,
+			&hit.Rank,
+			&hit.Highlights.Name,
+			&hit.Highlights.Description,
 		)
 		if err != nil {
+			return nil, 0, err
+		}
+		hit.Highlights.Name = markHighlights(hit.Highlights.Name)
+		hit.Highlights.Description = markHighlights(hit.Highlights.Description)
+		hits = append(hits, hit)
+	}
+	return hits, total, rows.Err()
+}
+
+// markHighlights HTML-escapes a headline and turns its match markers into
+// <mark> tags.
+func markHighlights(headline string) string {
+	var b strings.Builder
+	marked := false
+	for {
+		i := strings.IndexAny(headline, highlightStart+highlightStop)
+		if i < 0 {
+			b.WriteString(html.EscapeString(headline))
+			break
+		}
+		b.WriteString(html.EscapeString(headline[:i]))
+		switch {
+		case headline[i] == highlightStart[0] && !marked:
+			b.WriteString("<mark>")
+			marked = true
+		case headline[i] == highlightStop[0] && marked:
+			b.WriteString("</mark>")
+			marked = false
+		}
+		headline = headline[i+1:]
+	}
+	if marked {
+		b.WriteString("</mark>")
+	}
+	return b.String()
+}
+
+func (r *productRepository) SuggestNames(query repositories.ProductSuggestionQuery) ([]models.ProductSuggestion, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The <% operator can use the trigram index but takes its threshold from
+	// a setting, which is scoped to this transaction
+	_, err = tx.Exec(`SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`, strconv.FormatFloat(query.MinScore, 'f', -1, 64))
+	if err != nil {
+		return nil, err
+	}
+
+	// Names differing only in case are suggested once
+	rows, err := tx.Query(`
+		SELECT MIN(name) AS name, MAX(word_similarity($1, name)) AS score
+		FROM products
+		WHERE $1 <% name
+		GROUP BY LOWER(name)
+		ORDER BY score DESC, name
+		LIMIT $2
+	`, query.Input, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []models.ProductSuggestion{}
+	for rows.Next() {
+		var suggestion models.ProductSuggestion
+		if err := rows.Scan(&suggestion.Name, &suggestion.Score); err != nil {
 			return nil, err
 		}
-		products = append(products, product)
+		suggestions = append(suggestions, suggestion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
-	return products, nil
+
+	return suggestions, tx.Commit()
 }
 
 func (r *productRepository) Update(product *models.Product) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE products
 		SET name = $1, description = $2, price = $3, updated_at = $4
 		WHERE id = $5
 	`
-	result, err := r.db.Exec(
+	result, err := tx.Exec(
 		query,
 		product.Name,
 		product.Description,
@@ -114,6 +374,34 @@ func (r *productRepository) Update(product *models.Product) error {
 		return errors.New("product not found")
 	}
 
+	if err := setProductCategories(tx, product); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// setProductCategories replaces the product's categories with its primary
+// and secondary ones.
+func setProductCategories(tx *sql.Tx, product *models.Product) error {
+	if _, err := tx.Exec(`DELETE FROM product_categories WHERE product_id = $1`, product.ID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO product_categories (product_id, category_id, is_primary)
+		VALUES ($1, $2, $3)
+	`
+	if product.PrimaryCategoryID != nil {
+		if _, err := tx.Exec(query, product.ID, *product.PrimaryCategoryID, true); err != nil {
+			return categoryWriteError(err)
+		}
+	}
+	for _, categoryID := range product.SecondaryCategoryIDs {
+		if _, err := tx.Exec(query, product.ID, categoryID, false); err != nil {
+			return categoryWriteError(err)
+		}
+	}
 	return nil
 }
 
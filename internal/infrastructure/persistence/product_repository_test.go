@@ -0,0 +1,35 @@
+package persistence
+
+import "testing"
+
+func TestMarkHighlights(t *testing.T) {
+	const start, stop = highlightStart, highlightStop
+
+	tests := []struct {
+		name     string
+		headline string
+		want     string
+	}{
+		{"no matches", "Office Chair", "Office Chair"},
+		{"one match", "Office " + start + "Chair" + stop, "Office <mark>Chair</mark>"},
+		{"several matches", start + "Office" + stop + " " + start + "Chair" + stop, "<mark>Office</mark> <mark>Chair</mark>"},
+		{"empty", "", ""},
+		{"stored markup is escaped", `<script>alert("x")</script>`, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;"},
+		{"markup inside a match is escaped", start + "<b>Chair</b>" + stop, "<mark>&lt;b&gt;Chair&lt;/b&gt;</mark>"},
+		{"stored mark tags are escaped", "<mark>Chair</mark>", "&lt;mark&gt;Chair&lt;/mark&gt;"},
+		{"entities are escaped", "Tom & Jerry's", "Tom &amp; Jerry&#39;s"},
+		{"unterminated match is closed", "Office " + start + "Chair", "Office <mark>Chair</mark>"},
+		{"stray stop marker is dropped", "Office" + stop + " Chair", "Office Chair"},
+		{"repeated start marker is dropped", start + "Office " + start + "Chair" + stop, "<mark>Office Chair</mark>"},
+		{"repeated stop marker is dropped", start + "Office" + stop + stop + " Chair", "<mark>Office</mark> Chair"},
+		{"markers around stored markup", start + "<" + stop + "img>", "<mark>&lt;</mark>img&gt;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markHighlights(tt.headline); got != tt.want {
+				t.Errorf("markHighlights(%q) = %q, want %q", tt.headline, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package persistence
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows so that a single
+// scan helper can serve single-row lookups and list queries.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+type signingKeyRepository struct {
+	db *sql.DB
+}
+
+func NewSigningKeyRepository(db *sql.DB) repositories.SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+func (r *signingKeyRepository) Create(key *models.SigningKey) error {
+	query := `
+		INSERT INTO signing_keys (kid, algorithm, private_key, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := r.db.Exec(query, key.ID, key.Algorithm, key.PrivateKey, key.CreatedAt)
+	return err
+}
+
+func (r *signingKeyRepository) FindValid() ([]models.SigningKey, error) {
+	query := `
+		SELECT kid, algorithm, private_key, created_at, expires_at
+		FROM signing_keys
+		WHERE expires_at IS NULL OR expires_at > NOW()
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.SigningKey
+	for rows.Next() {
+		var key models.SigningKey
+		err := rows.Scan(
+			&key.ID,
+			&key.Algorithm,
+			&key.PrivateKey,
+			&key.CreatedAt,
+			&key.ExpiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *signingKeyRepository) RetireAllExcept(kid string, expiresAt time.Time) error {
+	query := `
+		UPDATE signing_keys
+		SET expires_at = GREATEST($1, retain_until)
+		WHERE kid <> $2 AND expires_at IS NULL
+	`
+	_, err := r.db.Exec(query, expiresAt, kid)
+	return err
+}
+
+func (r *signingKeyRepository) RetainUntil(kid string, until time.Time) error {
+	query := `
+		UPDATE signing_keys
+		SET retain_until = GREATEST(retain_until, $1),
+			expires_at = CASE WHEN expires_at IS NULL THEN NULL ELSE GREATEST(expires_at, $1) END
+		WHERE kid = $2
+	`
+	_, err := r.db.Exec(query, until, kid)
+	return err
+}
+
+func (r *signingKeyRepository) DeleteExpired() error {
+	_, err := r.db.Exec(`DELETE FROM signing_keys WHERE expires_at < NOW()`)
+	return err
+}
@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
+)
+
+type tokenDenylistRepository struct {
+	db *sql.DB
+}
+
+func NewTokenDenylistRepository(db *sql.DB) repositories.TokenDenylistRepository {
+	return &tokenDenylistRepository{db: db}
+}
+
+func (r *tokenDenylistRepository) Add(token *models.RevokedToken) error {
+	query := `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (jti) DO NOTHING
+	`
+	_, err := r.db.Exec(query, token.JTI, token.UserID, token.ExpiresAt, token.RevokedAt)
+	return err
+}
+
+func (r *tokenDenylistRepository) IsRevoked(jti string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`
+	if err := r.db.QueryRow(query, jti).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *tokenDenylistRepository) RevokeAllForUser(userID string, before time.Time) error {
+	query := `
+		INSERT INTO user_token_revocations (user_id, revoked_before)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET revoked_before = EXCLUDED.revoked_before
+	`
+	_, err := r.db.Exec(query, userID, before)
+	return err
+}
+
+func (r *tokenDenylistRepository) RevokedBefore(userID string) (*time.Time, error) {
+	var before time.Time
+	query := `SELECT revoked_before FROM user_token_revocations WHERE user_id = $1`
+	err := r.db.QueryRow(query, userID).Scan(&before)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &before, nil
+}
+
+func (r *tokenDenylistRepository) RevokeAllForClient(clientID, userID string, before, expiresAt time.Time) error {
+	query := `
+		INSERT INTO oauth_token_revocations (client_id, user_id, revoked_before, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (client_id, user_id) DO UPDATE SET revoked_before = EXCLUDED.revoked_before, expires_at = EXCLUDED.expires_at
+	`
+	_, err := r.db.Exec(query, clientID, userID, before, expiresAt)
+	return err
+}
+
+func (r *tokenDenylistRepository) ClientRevokedBefore(clientID, userID string) (*time.Time, error) {
+	var before sql.NullTime
+	query := `
+		SELECT MAX(revoked_before)
+		FROM oauth_token_revocations
+		WHERE client_id = $1 AND user_id IN ($2, '')
+	`
+	if err := r.db.QueryRow(query, clientID, userID).Scan(&before); err != nil {
+		return nil, err
+	}
+	if !before.Valid {
+		return nil, nil
+	}
+	return &before.Time, nil
+}
+
+func (r *tokenDenylistRepository) DeleteExpired() error {
+	if _, err := r.db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(`DELETE FROM oauth_token_revocations WHERE expires_at < NOW()`)
+	return err
+}
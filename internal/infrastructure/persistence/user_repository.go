@@ -3,12 +3,16 @@ package persistence
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/prakoso-id/go-windsurf/internal/domain/models"
 	"github.com/prakoso-id/go-windsurf/internal/domain/repositories"
 )
 
+const userColumns = `id, email, password, name, role, email_verified_at, disabled_at, deletion_scheduled_at, created_at, updated_at`
+
 type userRepository struct {
 	db *sql.DB
 }
@@ -17,64 +21,112 @@ func NewUserRepository(db *sql.DB) repositories.UserRepository {
 	return &userRepository{db: db}
 }
 
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.EmailVerifiedAt, &user.DisabledAt, &user.DeletionScheduledAt, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepository) Create(user *models.User) error {
 	query := `
-		INSERT INTO users (id, email, password, name, created_at, updated_at)
-		VALUES ($1, $2, crypt($3, gen_salt('bf')), $4, $5, $6)
+		INSERT INTO users (id, email, password, name, role, email_verified_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
-	_, err := r.db.Exec(query, user.ID, user.Email, user.Password, user.Name, user.CreatedAt, user.UpdatedAt)
+	_, err := r.db.Exec(query, user.ID, user.Email, user.PasswordHash, user.Name, user.Role, user.EmailVerifiedAt, user.CreatedAt, user.UpdatedAt)
 	return err
 }
 
 func (r *userRepository) FindByID(id string) (*models.User, error) {
-	var user models.User
 	query := `
-		SELECT id, email, name, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1
 	`
-	err := r.db.QueryRow(query, id).Scan(&user.ID, &user.Email, &user.Name, &user.CreatedAt, &user.UpdatedAt)
+	user, err := scanUser(r.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	return user, nil
 }
 
 func (r *userRepository) FindByEmail(email string) (*models.User, error) {
-	var user models.User
 	query := `
-		SELECT id, email, name, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE email = $1
 	`
-	err := r.db.QueryRow(query, email).Scan(&user.ID, &user.Email, &user.Name, &user.CreatedAt, &user.UpdatedAt)
+	user, err := scanUser(r.db.QueryRow(query, email))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	return user, nil
 }
 
-func (r *userRepository) FindByEmailAndPassword(email, password string) (*models.User, error) {
-	var user models.User
-	query := `
-		SELECT id, email, name, created_at, updated_at
-		FROM users
-		WHERE email = $1 AND password = crypt($2, password)
-	`
-	err := r.db.QueryRow(query, email, password).Scan(&user.ID, &user.Email, &user.Name, &user.CreatedAt, &user.UpdatedAt)
-	if err == sql.ErrNoRows {
-		return nil, errors.New("invalid credentials")
+func (r *userRepository) List(filter repositories.UserFilter) ([]models.User, int, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Search != "" {
+		args = append(args, "%"+escapeLike(filter.Search)+"%")
+		conditions = append(conditions, fmt.Sprintf("(email ILIKE $%d OR name ILIKE $%d)", len(args), len(args)))
+	}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		conditions = append(conditions, fmt.Sprintf("role = $%d", len(args)))
 	}
+	if filter.Disabled != nil {
+		if *filter.Disabled {
+			conditions = append(conditions, "disabled_at IS NOT NULL")
+		} else {
+			conditions = append(conditions, "disabled_at IS NULL")
+		}
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM users `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT `+userColumns+`
+		FROM users
+		%s
+		ORDER BY created_at, id
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+	rows, err := r.db.Query(query, append(args, filter.Limit, filter.Offset)...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return &user, nil
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, *user)
+	}
+	return users, total, rows.Err()
+}
+
+// escapeLike makes the LIKE wildcards in s match literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
 }
 
 func (r *userRepository) Update(user *models.User) error {
@@ -99,13 +151,79 @@ func (r *userRepository) Update(user *models.User) error {
 	return nil
 }
 
-func (r *userRepository) UpdatePassword(id string, newPassword string) error {
+func (r *userRepository) UpdatePassword(id string, passwordHash string) error {
+	query := `
+		UPDATE users
+		SET password = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+	result, err := r.db.Exec(query, passwordHash, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func (r *userRepository) UpdateRole(id string, role models.Role) error {
+	query := `
+		UPDATE users
+		SET role = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+	result, err := r.db.Exec(query, role, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func (r *userRepository) MarkEmailVerified(id string) error {
+	query := `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+		WHERE id = $1
+	`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func (r *userRepository) SetDisabled(id string, disabled bool) error {
 	query := `
 		UPDATE users
-		SET password = crypt($1, gen_salt('bf')), updated_at = NOW()
+		SET disabled_at = CASE WHEN $1 THEN COALESCE(disabled_at, NOW()) END, updated_at = NOW()
 		WHERE id = $2
 	`
-	result, err := r.db.Exec(query, newPassword, id)
+	result, err := r.db.Exec(query, disabled, id)
 	if err != nil {
 		return err
 	}
@@ -121,6 +239,109 @@ func (r *userRepository) UpdatePassword(id string, newPassword string) error {
 	return nil
 }
 
+func (r *userRepository) ScheduleDeletion(id string, at *time.Time) error {
+	query := `
+		UPDATE users
+		SET deletion_scheduled_at = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+	result, err := r.db.Exec(query, at, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func (r *userRepository) FindDueForDeletion(before time.Time) ([]models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE deletion_scheduled_at <= $1
+		ORDER BY deletion_scheduled_at
+	`
+	rows, err := r.db.Query(query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+func (r *userRepository) ClaimMagicLinkEmail(id string, interval time.Duration) (bool, error) {
+	query := `
+		UPDATE users
+		SET magic_link_sent_at = NOW()
+		WHERE id = $1
+		AND (magic_link_sent_at IS NULL OR magic_link_sent_at <= NOW() - make_interval(secs => $2))
+	`
+	result, err := r.db.Exec(query, id, interval.Seconds())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *userRepository) ClaimPasswordResetEmail(id string, interval time.Duration) (bool, error) {
+	query := `
+		UPDATE users
+		SET password_reset_sent_at = NOW()
+		WHERE id = $1
+		AND (password_reset_sent_at IS NULL OR password_reset_sent_at <= NOW() - make_interval(secs => $2))
+	`
+	result, err := r.db.Exec(query, id, interval.Seconds())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *userRepository) ClaimVerificationEmail(id string, interval time.Duration) (bool, error) {
+	query := `
+		UPDATE users
+		SET verification_sent_at = NOW()
+		WHERE id = $1
+		AND (verification_sent_at IS NULL OR verification_sent_at <= NOW() - make_interval(secs => $2))
+	`
+	result, err := r.db.Exec(query, id, interval.Seconds())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
 func (r *userRepository) Delete(id string) error {
 	query := `DELETE FROM users WHERE id = $1`
 	result, err := r.db.Exec(query, id)
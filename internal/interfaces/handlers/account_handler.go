@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
+)
+
+type AccountHandler struct {
+	accountService services.AccountService
+}
+
+func NewAccountHandler(accountService services.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// ExportData downloads everything held about the user, as a single JSON
+// document or, with format=zip, as an archive with one JSON file per kind of
+// data.
+func (h *AccountHandler) ExportData(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", "format must be json or zip")
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	export, err := h.accountService.Export(userID.(string))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to export data", err.Error())
+		return
+	}
+
+	var data []byte
+	contentType := "application/json"
+	if format == "zip" {
+		data, err = exportArchive(export)
+		contentType = "application/zip"
+	} else {
+		data, err = json.MarshalIndent(export, "", "  ")
+	}
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to export data", err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("account-export-%s.%s", export.ExportedAt.UTC().Format("20060102"), format)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func exportArchive(export *services.AccountExport) ([]byte, error) {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"identities.json", export.Identities},
+		{"two_factor.json", export.TwoFactor},
+		{"sessions.json", export.Sessions},
+		{"personal_access_tokens.json", export.PersonalAccessTokens},
+		{"api_keys.json", export.APIKeys},
+		{"oauth_clients.json", export.OAuthClients},
+		{"oauth_consents.json", export.OAuthConsents},
+		{"products.json", export.Products},
+		{"audit_events.json", export.AuditEvents},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ScheduleDeletion asks for the account to be deleted once the cooling-off
+// period has passed.
+func (h *AccountHandler) ScheduleDeletion(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	deleteAt, err := h.accountService.ScheduleDeletion(userID.(string))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to schedule account deletion", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Account deletion scheduled", gin.H{
+		"delete_at": deleteAt,
+	})
+}
+
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.accountService.CancelDeletion(userID.(string)); err != nil {
+		if errors.Is(err, services.ErrDeletionNotScheduled) {
+			response.Error(c, http.StatusConflict, "Account deletion is not scheduled", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to cancel account deletion", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Account deletion cancelled", nil)
+}
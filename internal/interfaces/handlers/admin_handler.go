@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
+)
+
+type AdminHandler struct {
+	adminService services.AdminService
+	loginGuard   services.LoginGuard
+}
+
+func NewAdminHandler(adminService services.AdminService, loginGuard services.LoginGuard) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		loginGuard:   loginGuard,
+	}
+}
+
+// ListUsers returns a page of users, optionally filtered by a search term
+// matching email or name, by role and by whether they are disabled.
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	var query services.UserQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	page, err := h.adminService.ListUsers(query)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Failed to get users", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Users retrieved successfully", page)
+}
+
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	user, err := h.adminService.GetUser(c.Param("id"))
+	if err != nil {
+		h.userError(c, "Failed to get user", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User retrieved successfully", user)
+}
+
+func (h *AdminHandler) DisableUser(c *gin.Context) {
+	adminID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.adminService.DisableUser(adminID.(string), c.Param("id")); err != nil {
+		h.userError(c, "Failed to disable user", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User disabled successfully", nil)
+}
+
+func (h *AdminHandler) EnableUser(c *gin.Context) {
+	adminID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.adminService.EnableUser(adminID.(string), c.Param("id")); err != nil {
+		h.userError(c, "Failed to enable user", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User enabled successfully", nil)
+}
+
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	adminID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.adminService.ForcePasswordReset(adminID.(string), c.Param("id")); err != nil {
+		h.userError(c, "Failed to force password reset", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Password reset forced successfully", nil)
+}
+
+func (h *AdminHandler) UpdateUserRole(c *gin.Context) {
+	type updateRoleRequest struct {
+		Role models.Role `json:"role" binding:"required,oneof=admin editor viewer"`
+	}
+
+	var req updateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	adminID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.adminService.UpdateRole(adminID.(string), c.Param("id"), req.Role); err != nil {
+		h.userError(c, "Failed to update role", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Role updated successfully", nil)
+}
+
+func (h *AdminHandler) DeleteUser(c *gin.Context) {
+	adminID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.adminService.DeleteUser(adminID.(string), c.Param("id")); err != nil {
+		h.userError(c, "Failed to delete user", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User deleted successfully", nil)
+}
+
+// ImpersonateUser issues a short-lived token for acting as the user. Every
+// request made with it is recorded in the audit log.
+func (h *AdminHandler) ImpersonateUser(c *gin.Context) {
+	type impersonateRequest struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+
+	var req impersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	adminID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	token, err := h.adminService.Impersonate(adminID.(string), c.Param("id"), req.Reason)
+	if err != nil {
+		h.userError(c, "Failed to impersonate user", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Impersonation started", token)
+}
+
+// UnlockUser clears the failed login attempts that locked a user's account.
+// Lockouts of the IP addresses involved expire on their own.
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	adminID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.loginGuard.Unlock(c.Param("id"), adminID.(string)); err != nil {
+		response.Error(c, http.StatusBadRequest, "Failed to unlock user", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User unlocked successfully", nil)
+}
+
+func (h *AdminHandler) userError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		response.Error(c, http.StatusNotFound, message, err.Error())
+	case errors.Is(err, services.ErrCannotManageSelf), errors.Is(err, services.ErrCannotImpersonate):
+		response.Error(c, http.StatusForbidden, message, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
+)
+
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	type createAPIKeyRequest struct {
+		Name   string              `json:"name" binding:"required"`
+		Scopes []models.Permission `json:"scopes" binding:"required,min=1"`
+		// Zero creates a key that does not expire
+		ExpiresInDays int `json:"expires_in_days" binding:"min=0,max=365"`
+	}
+
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+	accessClaims := claims.(*services.AccessClaims)
+
+	// A credential can only create keys that are at most as powerful as itself
+	if !models.ScopeIncludes(accessClaims.Scopes, req.Scopes) {
+		response.Error(c, http.StatusForbidden, "Forbidden", services.ErrScopeNotAllowed.Error())
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	key, apiKey, err := h.apiKeyService.Create(accessClaims.UserID, req.Name, req.Scopes, ttl)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Failed to create API key", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "API key created successfully", gin.H{
+		"key":     key,
+		"api_key": apiKey,
+	})
+}
+
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	keys, err := h.apiKeyService.List(userID.(string))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get API keys", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "API keys retrieved successfully", keys)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.apiKeyService.Revoke(userID.(string), c.Param("id")); err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			response.Error(c, http.StatusNotFound, "API key not found", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to revoke API key", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "API key revoked successfully", nil)
+}
@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"io"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prakoso-id/go-windsurf/internal/application/services"
@@ -11,12 +16,20 @@ import (
 type AuthHandler struct {
 	authService services.AuthService
 	userService services.UserService
+	mfaService  services.MFAService
+	loginGuard  services.LoginGuard
+	oidcService services.OIDCService
+	magicLinks  services.MagicLinkService
 }
 
-func NewAuthHandler(authService services.AuthService, userService services.UserService) *AuthHandler {
+func NewAuthHandler(authService services.AuthService, userService services.UserService, mfaService services.MFAService, loginGuard services.LoginGuard, oidcService services.OIDCService, magicLinks services.MagicLinkService) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
 		userService: userService,
+		mfaService:  mfaService,
+		loginGuard:  loginGuard,
+		oidcService: oidcService,
+		magicLinks:  magicLinks,
 	}
 }
 
@@ -32,47 +45,441 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	if !checkLoginAllowed(c, h.loginGuard, req.Email) {
+		return
+	}
+
 	// First verify credentials and get user ID
 	userID, err := h.userService.Login(req.Email, req.Password)
+	if errors.Is(err, services.ErrAccountDisabled) {
+		response.Error(c, http.StatusForbidden, "Login failed", err.Error())
+		return
+	}
 	if err != nil {
+		recordLoginFailure(c, h.loginGuard, req.Email)
 		response.Error(c, http.StatusUnauthorized, "Login failed", err.Error())
 		return
 	}
 
-	// Generate JWT token
-	token, err := h.authService.GenerateToken(userID)
+	h.beginLogin(c, userID, req.Email)
+}
+
+// OIDCLogin redirects the browser to the identity provider named in the URL.
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	authURL, err := h.oidcService.Begin(c.Param("provider"))
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to generate token", err.Error())
+		if errors.Is(err, services.ErrUnknownIdentityProvider) {
+			response.Error(c, http.StatusNotFound, "Login failed", err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadGateway, "Login failed", err.Error())
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback is the redirect URI registered with identity providers. It
+// completes the login the same way as a password login would.
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	if errCode := c.Query("error"); errCode != "" {
+		response.Error(c, http.StatusUnauthorized, "Login failed", errCode+": "+c.Query("error_description"))
+		return
+	}
+
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", "missing state or code")
 		return
 	}
 
-	response.Success(c, http.StatusOK, "Login successful", gin.H{"token": token})
+	user, err := h.oidcService.Complete(c.Param("provider"), state, code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownIdentityProvider):
+			response.Error(c, http.StatusNotFound, "Login failed", err.Error())
+		case errors.Is(err, services.ErrInvalidOIDCState):
+			response.Error(c, http.StatusBadRequest, "Login failed", err.Error())
+		case errors.Is(err, services.ErrIdentityNotLinkable):
+			response.Error(c, http.StatusConflict, "Login failed", err.Error())
+		default:
+			response.Error(c, http.StatusUnauthorized, "Login failed", err.Error())
+		}
+		return
+	}
+
+	h.beginLogin(c, user.ID, user.Email)
 }
 
-func AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
-		if tokenString == "" {
-			response.Error(c, http.StatusUnauthorized, "Authorization required", "authorization header required")
-			c.Abort()
+// RequestMagicLink responds the same way whether or not the email is
+// registered, so it cannot be used to find out who has an account.
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	type magicLinkRequest struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	var req magicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	if err := h.magicLinks.RequestLink(req.Email); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to send login link", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "If the email is registered, a login link has been sent", nil)
+}
+
+// MagicLinkLogin exchanges the token from a login link for the same response
+// as a password login. The token is posted by a frontend page or, when the
+// link points here directly, given in the query string.
+func (h *AuthHandler) MagicLinkLogin(c *gin.Context) {
+	type magicLinkLoginRequest struct {
+		Token string `json:"token" form:"token" binding:"required"`
+	}
+
+	var req magicLinkLoginRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	user, err := h.magicLinks.Login(req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidMagicLink):
+			response.Error(c, http.StatusUnauthorized, "Login failed", err.Error())
+			return
+		case errors.Is(err, services.ErrAccountDisabled):
+			response.Error(c, http.StatusForbidden, "Login failed", err.Error())
 			return
 		}
+		response.Error(c, http.StatusInternalServerError, "Login failed", err.Error())
+		return
+	}
+
+	h.beginLogin(c, user.ID, user.Email)
+}
 
-		userID, err := authService.ValidateToken(tokenString)
+// beginLogin is called once the user's first factor has been verified. Users
+// with two-factor authentication get an MFA challenge, everyone else a token
+// pair.
+func (h *AuthHandler) beginLogin(c *gin.Context, userID, email string) {
+	mfaEnabled, err := h.mfaService.IsEnabled(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Login failed", err.Error())
+		return
+	}
+	if mfaEnabled {
+		mfaToken, expiresIn, err := h.authService.GenerateMFAToken(userID)
 		if err != nil {
-			response.Error(c, http.StatusUnauthorized, "Invalid token", err.Error())
-			c.Abort()
+			response.Error(c, http.StatusInternalServerError, "Failed to generate token", err.Error())
+			return
+		}
+
+		response.Success(c, http.StatusOK, "Two-factor authentication required", gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   expiresIn,
+		})
+		return
+	}
+
+	h.completeLogin(c, userID, email)
+}
+
+// LoginMFA completes a login started by Login with a TOTP or recovery code.
+// The MFA token is single use; a wrong code requires logging in again.
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	type loginMFARequest struct {
+		MFAToken string `json:"mfa_token" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+
+	var req loginMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	userID, err := h.authService.ConsumeMFAToken(req.MFAToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMFAToken) {
+			response.Error(c, http.StatusUnauthorized, "Login failed", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Login failed", err.Error())
+		return
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil || user == nil {
+		response.Error(c, http.StatusUnauthorized, "Login failed", "user not found")
+		return
+	}
+
+	// Wrong codes count towards the same lockout as wrong passwords
+	if !checkLoginAllowed(c, h.loginGuard, user.Email) {
+		return
+	}
+
+	if err := h.mfaService.Verify(userID, req.Code); err != nil {
+		if errors.Is(err, services.ErrInvalidMFACode) || errors.Is(err, services.ErrMFANotEnabled) {
+			recordLoginFailure(c, h.loginGuard, user.Email)
+			response.Error(c, http.StatusUnauthorized, "Login failed", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Login failed", err.Error())
+		return
+	}
+
+	h.completeLogin(c, userID, user.Email)
+}
+
+// Reauthenticate checks the password, or a fresh login link for users who
+// signed up through an identity provider and have no usable password, and a
+// second factor if the user has enrolled one. It issues a short-lived token
+// that allows sensitive operations such as changing the password. Failures
+// count towards the login lockout.
+func (h *AuthHandler) Reauthenticate(c *gin.Context) {
+	type reauthenticateRequest struct {
+		Password       string `json:"password"`
+		MagicLinkToken string `json:"magic_link_token"`
+		Code           string `json:"code"`
+	}
+
+	var req reauthenticateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+	if (req.Password == "") == (req.MagicLinkToken == "") {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", "give either password or magic_link_token")
+		return
+	}
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+	accessClaims := claims.(*services.AccessClaims)
+
+	user, err := h.userService.GetUserByID(accessClaims.UserID)
+	if err != nil || user == nil {
+		response.Error(c, http.StatusUnauthorized, "Reauthentication failed", "user not found")
+		return
+	}
+
+	if !checkLoginAllowed(c, h.loginGuard, user.Email) {
+		return
+	}
+
+	if req.MagicLinkToken != "" {
+		linkUser, err := h.magicLinks.Login(req.MagicLinkToken)
+		if err != nil && !errors.Is(err, services.ErrInvalidMagicLink) {
+			response.Error(c, http.StatusInternalServerError, "Reauthentication failed", err.Error())
+			return
+		}
+		// A link sent to someone else's address proves nothing about this user
+		if err != nil || linkUser.ID != user.ID {
+			recordLoginFailure(c, h.loginGuard, user.Email)
+			response.Error(c, http.StatusUnauthorized, "Reauthentication failed", services.ErrInvalidMagicLink.Error())
+			return
+		}
+	} else if err := h.userService.VerifyPassword(user.ID, req.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			recordLoginFailure(c, h.loginGuard, user.Email)
+			response.Error(c, http.StatusUnauthorized, "Reauthentication failed", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Reauthentication failed", err.Error())
+		return
+	}
+
+	mfaEnabled, err := h.mfaService.IsEnabled(user.ID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Reauthentication failed", err.Error())
+		return
+	}
+	if mfaEnabled {
+		if req.Code == "" {
+			response.Error(c, http.StatusUnauthorized, "Reauthentication failed", "two-factor authentication code required")
+			return
+		}
+		if err := h.mfaService.Verify(user.ID, req.Code); err != nil {
+			if errors.Is(err, services.ErrInvalidMFACode) {
+				recordLoginFailure(c, h.loginGuard, user.Email)
+				response.Error(c, http.StatusUnauthorized, "Reauthentication failed", err.Error())
+				return
+			}
+			response.Error(c, http.StatusInternalServerError, "Reauthentication failed", err.Error())
+			return
+		}
+	}
+
+	if err := h.loginGuard.RecordSuccess(user.Email); err != nil {
+		log.Println("Error clearing failed logins:", err)
+	}
+
+	token, err := h.authService.IssueReauthenticatedToken(accessClaims)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNoLoginSession),
+			errors.Is(err, services.ErrAccountDisabled),
+			errors.Is(err, services.ErrScopeNotAllowed):
+			response.Error(c, http.StatusForbidden, "Reauthentication failed", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Reauthentication failed", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Reauthenticated successfully", token)
+}
+
+// checkLoginAllowed responds with 429 and returns false while the account or
+// the client's IP address is locked out. Anything that checks a password or
+// second factor goes through it, so that guesses share one lockout.
+func checkLoginAllowed(c *gin.Context, loginGuard services.LoginGuard, email string) bool {
+	wait, err := loginGuard.Check(email, c.ClientIP())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Login failed", err.Error())
+		return false
+	}
+	if wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		response.Error(c, http.StatusTooManyRequests, "Login failed", services.ErrTooManyLoginAttempts.Error())
+		return false
+	}
+	return true
+}
+
+func recordLoginFailure(c *gin.Context, loginGuard services.LoginGuard, email string) {
+	if err := loginGuard.RecordFailure(email, c.ClientIP()); err != nil {
+		log.Println("Error recording failed login:", err)
+	}
+}
+
+// completeLogin clears the account's failed attempts once every factor has
+// been verified and issues the token pair.
+func (h *AuthHandler) completeLogin(c *gin.Context, userID, email string) {
+	if err := h.loginGuard.RecordSuccess(email); err != nil {
+		log.Println("Error clearing failed logins:", err)
+	}
+
+	// Issue an access token and start a new session
+	tokens, err := h.authService.IssueTokenPair(userID, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrAccountDisabled) {
+			response.Error(c, http.StatusForbidden, "Login failed", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to generate token", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Login successful", tokens)
+}
+
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	type refreshRequest struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	var req refreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	tokens, err := h.authService.RefreshTokens(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRefreshToken),
+			errors.Is(err, services.ErrRefreshTokenExpired),
+			errors.Is(err, services.ErrRefreshTokenReused):
+			response.Error(c, http.StatusUnauthorized, "Token refresh failed", err.Error())
+		case errors.Is(err, services.ErrAccountDisabled):
+			response.Error(c, http.StatusForbidden, "Token refresh failed", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Token refresh failed", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Token refreshed successfully", tokens)
+}
+
+// Logout revokes the access token used for the request and ends the session
+// it belongs to. Tokens issued before sessions existed have no sid claim;
+// for those the login is ended when the refresh token is supplied.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	type logoutRequest struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	var req logoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+	accessClaims := claims.(*services.AccessClaims)
+	if accessClaims.TokenID == "" {
+		response.Error(c, http.StatusBadRequest, "Logout failed", "api keys cannot be logged out, revoke the key instead")
+		return
+	}
+
+	if err := h.authService.RevokeToken(accessClaims); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Logout failed", err.Error())
+		return
+	}
+
+	if accessClaims.SessionID != "" {
+		err := h.authService.RevokeSession(accessClaims.UserID, accessClaims.SessionID)
+		if err != nil && !errors.Is(err, services.ErrSessionNotFound) {
+			response.Error(c, http.StatusInternalServerError, "Logout failed", err.Error())
 			return
 		}
+	}
 
-		if userID == "" {
-			response.Error(c, http.StatusUnauthorized, "Invalid token", "token is not valid")
-			c.Abort()
+	if req.RefreshToken != "" {
+		if err := h.authService.RevokeRefreshToken(accessClaims.UserID, req.RefreshToken); err != nil {
+			response.Error(c, http.StatusInternalServerError, "Logout failed", err.Error())
 			return
 		}
+	}
+
+	response.Success(c, http.StatusOK, "Logout successful", nil)
+}
+
+// LogoutAll revokes every access and refresh token issued to the user.
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.authService.RevokeAllTokens(userID.(string)); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Logout failed", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Logged out from all devices", nil)
+}
 
-		// Store the validated userID in the context for later use
-		c.Set("userID", userID)
-		c.Next()
+func clientInfo(c *gin.Context) services.ClientInfo {
+	return services.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
 	}
 }
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
+)
+
+type CategoryHandler struct {
+	categoryService services.CategoryService
+}
+
+func NewCategoryHandler(categoryService services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{categoryService: categoryService}
+}
+
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	type createCategoryRequest struct {
+		Name        string `json:"name" binding:"required,max=255"`
+		Description string `json:"description"`
+		ParentID    *uint  `json:"parent_id"`
+	}
+
+	var req createCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	category := models.Category{
+		Name:        req.Name,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+	}
+	if err := h.categoryService.CreateCategory(&category); err != nil {
+		h.categoryError(c, "Failed to create category", err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Category created successfully", category)
+}
+
+// GetCategoryTree returns every category, nested under its parent.
+func (h *CategoryHandler) GetCategoryTree(c *gin.Context) {
+	tree, err := h.categoryService.GetTree()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get categories", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Categories retrieved successfully", tree)
+}
+
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	id := c.Param("id")
+	categoryID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid category ID", err.Error())
+		return
+	}
+
+	category, err := h.categoryService.GetCategory(uint(categoryID))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get category", err.Error())
+		return
+	}
+	if category == nil {
+		response.Error(c, http.StatusNotFound, "Category not found", services.ErrCategoryNotFound.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Category retrieved successfully", category)
+}
+
+// UpdateCategory replaces the category's name, description and parent.
+// Changing the parent moves the category along with its subcategories.
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	type updateCategoryRequest struct {
+		Name        string `json:"name" binding:"required,max=255"`
+		Description string `json:"description"`
+		ParentID    *uint  `json:"parent_id"`
+	}
+
+	id := c.Param("id")
+	categoryID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid category ID", err.Error())
+		return
+	}
+
+	var req updateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	category := models.Category{
+		ID:          uint(categoryID),
+		Name:        req.Name,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+	}
+	if err := h.categoryService.UpdateCategory(&category); err != nil {
+		h.categoryError(c, "Failed to update category", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Category updated successfully", category)
+}
+
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id := c.Param("id")
+	categoryID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid category ID", err.Error())
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(uint(categoryID)); err != nil {
+		h.categoryError(c, "Failed to delete category", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Category deleted successfully", nil)
+}
+
+func (h *CategoryHandler) categoryError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrCategoryNotFound):
+		response.Error(c, http.StatusNotFound, message, err.Error())
+	case errors.Is(err, services.ErrInvalidCategory):
+		response.Error(c, http.StatusBadRequest, message, err.Error())
+	case errors.Is(err, services.ErrCategoryNameTaken), errors.Is(err, services.ErrCategoryInUse):
+		response.Error(c, http.StatusConflict, message, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
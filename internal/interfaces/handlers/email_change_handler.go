@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
+)
+
+type EmailChangeHandler struct {
+	emailChangeService services.EmailChangeService
+}
+
+func NewEmailChangeHandler(emailChangeService services.EmailChangeService) *EmailChangeHandler {
+	return &EmailChangeHandler{emailChangeService: emailChangeService}
+}
+
+func (h *EmailChangeHandler) RequestChange(c *gin.Context) {
+	type changeEmailRequest struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	var req changeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.emailChangeService.RequestChange(userID.(string), req.Email); err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmailUnchanged):
+			response.Error(c, http.StatusBadRequest, "Failed to change email", err.Error())
+		case errors.Is(err, services.ErrEmailTaken):
+			response.Error(c, http.StatusConflict, "Failed to change email", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to change email", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Confirmation email sent to the new address", nil)
+}
+
+// ConfirmChange is the target of the link sent to the new address.
+func (h *EmailChangeHandler) ConfirmChange(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", "missing token")
+		return
+	}
+
+	if err := h.emailChangeService.Confirm(token); err != nil {
+		h.linkError(c, "Failed to confirm email change", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Email changed successfully", nil)
+}
+
+// RevertChange is the target of the link sent to the old address.
+func (h *EmailChangeHandler) RevertChange(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", "missing token")
+		return
+	}
+
+	if err := h.emailChangeService.Revert(token); err != nil {
+		h.linkError(c, "Failed to revert email change", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Email change reverted successfully", nil)
+}
+
+func (h *EmailChangeHandler) linkError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidEmailChangeToken), errors.Is(err, services.ErrEmailChangeNotApplicable):
+		response.Error(c, http.StatusBadRequest, message, err.Error())
+	case errors.Is(err, services.ErrEmailTaken):
+		response.Error(c, http.StatusConflict, message, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
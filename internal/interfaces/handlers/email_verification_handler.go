@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
+)
+
+type EmailVerificationHandler struct {
+	verificationService services.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(verificationService services.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{verificationService: verificationService}
+}
+
+// VerifyEmail is the target of the link in the verification email.
+func (h *EmailVerificationHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", "missing token")
+		return
+	}
+
+	if err := h.verificationService.Verify(token); err != nil {
+		if errors.Is(err, services.ErrInvalidVerifyToken) {
+			response.Error(c, http.StatusBadRequest, "Email verification failed", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Email verification failed", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Email verified successfully", nil)
+}
+
+func (h *EmailVerificationHandler) ResendVerification(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.verificationService.SendVerification(userID.(string)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmailAlreadyVerified):
+			response.Error(c, http.StatusConflict, "Failed to send verification email", err.Error())
+		case errors.Is(err, services.ErrVerificationThrottled):
+			response.Error(c, http.StatusTooManyRequests, "Failed to send verification email", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to send verification email", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Verification email sent", nil)
+}
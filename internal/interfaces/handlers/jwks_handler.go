@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+)
+
+type JWKSHandler struct {
+	keyManager services.KeyManager
+}
+
+func NewJWKSHandler(keyManager services.KeyManager) *JWKSHandler {
+	return &JWKSHandler{keyManager: keyManager}
+}
+
+// JWKS publishes the public keys that verify our access tokens. The body is a
+// plain RFC 7517 key set rather than the usual response envelope so that
+// standard JWT libraries can consume it directly.
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keyManager.JWKS())
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
+)
+
+type MFAHandler struct {
+	mfaService  services.MFAService
+	userService services.UserService
+	loginGuard  services.LoginGuard
+}
+
+func NewMFAHandler(mfaService services.MFAService, userService services.UserService, loginGuard services.LoginGuard) *MFAHandler {
+	return &MFAHandler{
+		mfaService:  mfaService,
+		userService: userService,
+		loginGuard:  loginGuard,
+	}
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	enabled, err := h.mfaService.IsEnabled(userID.(string))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get two-factor status", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Two-factor status retrieved successfully", gin.H{"enabled": enabled})
+}
+
+// Enroll returns a new TOTP secret. Two-factor authentication stays off until
+// the enrollment is confirmed with a code.
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	enrollment, err := h.mfaService.Enroll(userID.(string))
+	if err != nil {
+		if errors.Is(err, services.ErrMFAAlreadyEnabled) {
+			response.Error(c, http.StatusConflict, "Failed to enroll", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to enroll", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Two-factor enrollment started", enrollment)
+}
+
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	var req mfaCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	codes, err := h.mfaService.Confirm(userID.(string), req.Code)
+	if err != nil {
+		h.handleError(c, "Failed to enable two-factor authentication", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Two-factor authentication enabled", gin.H{"recovery_codes": codes})
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req mfaCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	email, ok := h.checkCodeAllowed(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(userID.(string), req.Code)
+	if err != nil {
+		h.codeFailed(c, email, "Failed to regenerate recovery codes", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Recovery codes regenerated", gin.H{"recovery_codes": codes})
+}
+
+// Disable turns two-factor authentication off. The route requires a recent
+// re-authentication, which covers the password, or a login link for users
+// without one; the code shows the second factor is still at hand.
+func (h *MFAHandler) Disable(c *gin.Context) {
+	var req mfaCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	email, ok := h.checkCodeAllowed(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.Disable(userID.(string), req.Code); err != nil {
+		h.codeFailed(c, email, "Failed to disable two-factor authentication", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+// checkCodeAllowed applies the login lockout to codes checked on behalf of a
+// signed-in user, so that a stolen token cannot be used to guess them. It
+// returns the user's email address, which the lockout is keyed by.
+func (h *MFAHandler) checkCodeAllowed(c *gin.Context, userID string) (string, bool) {
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to check two-factor code", err.Error())
+		return "", false
+	}
+	if user == nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not found")
+		return "", false
+	}
+	if !checkLoginAllowed(c, h.loginGuard, user.Email) {
+		return "", false
+	}
+	return user.Email, true
+}
+
+// codeFailed responds to an error from checking a code, counting wrong codes
+// towards the lockout.
+func (h *MFAHandler) codeFailed(c *gin.Context, email, message string, err error) {
+	if errors.Is(err, services.ErrInvalidMFACode) {
+		recordLoginFailure(c, h.loginGuard, email)
+	}
+	h.handleError(c, message, err)
+}
+
+func (h *MFAHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFACode):
+		response.Error(c, http.StatusUnauthorized, message, err.Error())
+	case errors.Is(err, services.ErrMFANotEnabled),
+		errors.Is(err, services.ErrMFAAlreadyEnabled):
+		response.Error(c, http.StatusConflict, message, err.Error())
+	default:
+		response.Error(c, http.StatusBadRequest, message, err.Error())
+	}
+}
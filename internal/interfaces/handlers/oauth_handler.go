@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
+)
+
+// OAuthHandler serves the OAuth2 authorization server. Client management,
+// authorization and consent endpoints use the API's usual response format;
+// the token, introspection and revocation endpoints are called by OAuth
+// client libraries and use the wire format of RFC 6749, 7662 and 7009.
+type OAuthHandler struct {
+	oauthService services.OAuthService
+}
+
+func NewOAuthHandler(oauthService services.OAuthService) *OAuthHandler {
+	return &OAuthHandler{oauthService: oauthService}
+}
+
+func (h *OAuthHandler) RegisterClient(c *gin.Context) {
+	type registerClientRequest struct {
+		Name         string              `json:"name" binding:"required"`
+		RedirectURIs []string            `json:"redirect_uris" binding:"required,min=1"`
+		Scopes       []models.Permission `json:"scopes" binding:"required,min=1"`
+		// Confidential clients get a secret; public clients such as mobile
+		// and single-page apps rely on PKCE alone
+		Confidential bool `json:"confidential"`
+	}
+
+	var req registerClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+	accessClaims := claims.(*services.AccessClaims)
+
+	// The client credentials grant acts as the owner, so a client can be no
+	// more powerful than the token that registered it
+	if !models.ScopeIncludes(accessClaims.Scopes, req.Scopes) {
+		response.Error(c, http.StatusForbidden, "Forbidden", services.ErrScopeNotAllowed.Error())
+		return
+	}
+
+	secret, client, err := h.oauthService.RegisterClient(accessClaims.UserID, req.Name, req.RedirectURIs, req.Scopes, req.Confidential)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Failed to register client", err.Error())
+		return
+	}
+
+	data := gin.H{"client": client}
+	if secret != "" {
+		data["client_secret"] = secret
+	}
+	response.Success(c, http.StatusCreated, "Client registered successfully", data)
+}
+
+func (h *OAuthHandler) ListClients(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	clients, err := h.oauthService.ListClients(userID.(string))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get clients", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Clients retrieved successfully", clients)
+}
+
+func (h *OAuthHandler) DeleteClient(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.oauthService.DeleteClient(userID.(string), c.Param("id")); err != nil {
+		if errors.Is(err, services.ErrOAuthClientNotFound) {
+			response.Error(c, http.StatusNotFound, "Client not found", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to delete client", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Client deleted successfully", nil)
+}
+
+// Authorize is called by the frontend with the query string of the client's
+// authorization request. It either returns where to redirect the user or
+// the client and scopes to ask the user to approve.
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	var req services.AuthorizationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	result, err := h.oauthService.Authorize(userID.(string), &req)
+	if err != nil {
+		h.authorizationError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Authorization request processed", result)
+}
+
+// Decide records the user's answer to the consent prompt.
+func (h *OAuthHandler) Decide(c *gin.Context) {
+	type decisionRequest struct {
+		services.AuthorizationRequest
+		Approve bool `json:"approve"`
+	}
+
+	var req decisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	result, err := h.oauthService.Decide(userID.(string), &req.AuthorizationRequest, req.Approve)
+	if err != nil {
+		h.authorizationError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Authorization request processed", result)
+}
+
+func (h *OAuthHandler) authorizationError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrOAuthClientNotFound) || errors.Is(err, services.ErrInvalidRedirectURI) {
+		response.Error(c, http.StatusBadRequest, "Invalid authorization request", err.Error())
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, "Authorization failed", err.Error())
+}
+
+func (h *OAuthHandler) Token(c *gin.Context) {
+	client, ok := h.authenticateClient(c)
+	if !ok {
+		return
+	}
+
+	var token *services.OAuthToken
+	var err error
+	switch c.PostForm("grant_type") {
+	case "authorization_code":
+		token, err = h.oauthService.ExchangeCode(client, c.PostForm("code"), c.PostForm("redirect_uri"), c.PostForm("code_verifier"))
+	case "client_credentials":
+		token, err = h.oauthService.ClientCredentials(client, c.PostForm("scope"))
+	default:
+		err = &services.OAuthError{Code: "unsupported_grant_type", Description: "supported grant types are authorization_code and client_credentials"}
+	}
+	if err != nil {
+		oauthError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	client, ok := h.authenticateClient(c)
+	if !ok {
+		return
+	}
+
+	introspection, err := h.oauthService.Introspect(client, c.PostForm("token"))
+	if err != nil {
+		oauthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, introspection)
+}
+
+func (h *OAuthHandler) Revoke(c *gin.Context) {
+	client, ok := h.authenticateClient(c)
+	if !ok {
+		return
+	}
+
+	if err := h.oauthService.RevokeToken(client, c.PostForm("token")); err != nil {
+		oauthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// authenticateClient accepts client credentials with HTTP Basic
+// authentication or in the form body.
+func (h *OAuthHandler) authenticateClient(c *gin.Context) (*models.OAuthClient, bool) {
+	clientID, clientSecret, ok := c.Request.BasicAuth()
+	if ok {
+		// RFC 6749 section 2.3.1 form-encodes both values
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = c.PostForm("client_id"), c.PostForm("client_secret")
+	}
+
+	client, err := h.oauthService.AuthenticateClient(clientID, clientSecret)
+	if err != nil {
+		if ok {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		oauthError(c, err)
+		return nil, false
+	}
+	return client, true
+}
+
+func (h *OAuthHandler) ListConsents(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	consents, err := h.oauthService.ListConsents(userID.(string))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get consents", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Consents retrieved successfully", consents)
+}
+
+func (h *OAuthHandler) RevokeConsent(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.oauthService.RevokeConsent(userID.(string), c.Param("client_id")); err != nil {
+		if errors.Is(err, services.ErrConsentNotFound) {
+			response.Error(c, http.StatusNotFound, "Consent not found", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to revoke consent", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Consent revoked successfully", nil)
+}
+
+func oauthError(c *gin.Context, err error) {
+	var oauthErr *services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		c.JSON(http.StatusInternalServerError, services.OAuthError{Code: "server_error", Description: err.Error()})
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == "invalid_client" {
+		status = http.StatusUnauthorized
+	}
+	c.JSON(status, oauthErr)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
+)
+
+type PasswordResetHandler struct {
+	resetService services.PasswordResetService
+}
+
+func NewPasswordResetHandler(resetService services.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{resetService: resetService}
+}
+
+// ForgotPassword responds the same way whether or not the email is
+// registered, so it cannot be used to find out who has an account.
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	type forgotPasswordRequest struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	var req forgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	if err := h.resetService.RequestReset(req.Email); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to request password reset", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "If the email is registered, a password reset link has been sent", nil)
+}
+
+// CheckResetToken is where reset links point when no frontend page is
+// configured. It tells whether the link still works; the new password is then
+// posted to ResetPassword along with the token.
+func (h *PasswordResetHandler) CheckResetToken(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", "missing token")
+		return
+	}
+
+	if err := h.resetService.CheckToken(token); err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			response.Error(c, http.StatusBadRequest, "Invalid password reset link", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to check password reset link", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Password reset link is valid; post the token with new_password to reset the password", nil)
+}
+
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	type resetPasswordRequest struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+
+	var req resetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	if err := h.resetService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		if passwordRejected(c, err) {
+			return
+		}
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			response.Error(c, http.StatusBadRequest, "Failed to reset password", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to reset password", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Password reset successfully", nil)
+}
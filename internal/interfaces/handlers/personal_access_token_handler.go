@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/domain/models"
+	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
+)
+
+type PersonalAccessTokenHandler struct {
+	tokenService services.PersonalAccessTokenService
+}
+
+func NewPersonalAccessTokenHandler(tokenService services.PersonalAccessTokenService) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{tokenService: tokenService}
+}
+
+func (h *PersonalAccessTokenHandler) CreateToken(c *gin.Context) {
+	type createTokenRequest struct {
+		Name          string              `json:"name" binding:"required"`
+		Scopes        []models.Permission `json:"scopes" binding:"required,min=1"`
+		ExpiresInDays int                 `json:"expires_in_days" binding:"required,min=1,max=365"`
+	}
+
+	var req createTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+	accessClaims := claims.(*services.AccessClaims)
+
+	// A token can only mint tokens that are at most as powerful as itself
+	if !models.ScopeIncludes(accessClaims.Scopes, req.Scopes) {
+		response.Error(c, http.StatusForbidden, "Forbidden", services.ErrScopeNotAllowed.Error())
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	token, pat, err := h.tokenService.Create(accessClaims.UserID, req.Name, req.Scopes, ttl)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Failed to create token", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Token created successfully", gin.H{
+		"token":                 token,
+		"personal_access_token": pat,
+	})
+}
+
+func (h *PersonalAccessTokenHandler) ListTokens(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	tokens, err := h.tokenService.List(userID.(string))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get tokens", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Tokens retrieved successfully", tokens)
+}
+
+func (h *PersonalAccessTokenHandler) RevokeToken(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.tokenService.Revoke(userID.(string), c.Param("id")); err != nil {
+		if errors.Is(err, services.ErrPersonalAccessTokenNotFound) {
+			response.Error(c, http.StatusNotFound, "Token not found", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to revoke token", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Token revoked successfully", nil)
+}
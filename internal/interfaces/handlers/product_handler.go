@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -25,7 +26,19 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+	ownerID := userID.(string)
+	product.OwnerID = &ownerID
+
 	if err := h.productService.CreateProduct(&product); err != nil {
+		if errors.Is(err, services.ErrInvalidProductCategories) {
+			response.Error(c, http.StatusBadRequest, "Failed to create product", err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Failed to create product", err.Error())
 		return
 	}
@@ -33,14 +46,83 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	response.Success(c, http.StatusCreated, "Product created successfully", product)
 }
 
+// GetAllProducts returns a page of products. Filters are name (substring),
+// category (including its subcategories), min_price, max_price,
+// created_after and created_before (RFC 3339); sort is one of name, price or
+// created_at, prefixed with "-" for descending order.
+// The next page is fetched by passing meta.next_cursor as cursor.
 func (h *ProductHandler) GetAllProducts(c *gin.Context) {
-	products, err := h.productService.ListProducts()
+	var query services.ProductQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	page, err := h.productService.ListProducts(query)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidProductQuery) {
+			response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			response.Error(c, http.StatusNotFound, "Category not found", err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Failed to get products", err.Error())
 		return
 	}
 
-	response.Success(c, http.StatusOK, "Products retrieved successfully", products)
+	response.SuccessWithMeta(c, http.StatusOK, "Products retrieved successfully", page.Products, gin.H{
+		"next_cursor": page.NextCursor,
+		"total":       page.Total,
+		"limit":       page.Limit,
+	})
+}
+
+// SearchProducts finds products matching q, most relevant first. q uses web
+// search syntax: quoted phrases, "or" and a leading "-" to exclude a word.
+func (h *ProductHandler) SearchProducts(c *gin.Context) {
+	var query services.ProductSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	page, err := h.productService.SearchProducts(query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidProductQuery) {
+			response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to search products", err.Error())
+		return
+	}
+
+	response.SuccessWithMeta(c, http.StatusOK, "Products retrieved successfully", page.Hits, gin.H{
+		"total":     page.Total,
+		"page":      page.Page,
+		"page_size": page.PageSize,
+	})
+}
+
+// Autocomplete suggests product names for what the user has typed so far,
+// tolerating typos. It is meant to be called on every keystroke.
+func (h *ProductHandler) Autocomplete(c *gin.Context) {
+	var query services.ProductSuggestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
+	suggestions, err := h.productService.SuggestProductNames(query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get suggestions", err.Error())
+		return
+	}
+
+	// Typing and then deleting a character repeats requests
+	c.Header("Cache-Control", "private, max-age=60")
+	response.Success(c, http.StatusOK, "Suggestions retrieved successfully", suggestions)
 }
 
 func (h *ProductHandler) GetProduct(c *gin.Context) {
@@ -76,6 +158,10 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	product.ID = uint(productID)
 
 	if err := h.productService.UpdateProduct(&product); err != nil {
+		if errors.Is(err, services.ErrInvalidProductCategories) {
+			response.Error(c, http.StatusBadRequest, "Failed to update product", err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Failed to update product", err.Error())
 		return
 	}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prakoso-id/go-windsurf/internal/application/services"
+	"github.com/prakoso-id/go-windsurf/internal/interfaces/http/response"
+)
+
+type SessionHandler struct {
+	authService services.AuthService
+}
+
+func NewSessionHandler(authService services.AuthService) *SessionHandler {
+	return &SessionHandler{authService: authService}
+}
+
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+	accessClaims := claims.(*services.AccessClaims)
+
+	sessions, err := h.authService.ListSessions(accessClaims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get sessions", err.Error())
+		return
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == accessClaims.SessionID
+	}
+
+	response.Success(c, http.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID.(string), c.Param("id")); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			response.Error(c, http.StatusNotFound, "Session not found", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to revoke session", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Session revoked successfully", nil)
+}
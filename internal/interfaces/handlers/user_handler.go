@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -9,11 +11,15 @@ import (
 )
 
 type UserHandler struct {
-	userService services.UserService
+	userService         services.UserService
+	verificationService services.EmailVerificationService
 }
 
-func NewUserHandler(userService services.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService services.UserService, verificationService services.EmailVerificationService) *UserHandler {
+	return &UserHandler{
+		userService:         userService,
+		verificationService: verificationService,
+	}
 }
 
 // Register godoc
@@ -29,7 +35,7 @@ func NewUserHandler(userService services.UserService) *UserHandler {
 func (h *UserHandler) Register(c *gin.Context) {
 	type registerRequest struct {
 		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required,min=6"`
+		Password string `json:"password" binding:"required"`
 		Name     string `json:"name" binding:"required"`
 	}
 
@@ -41,10 +47,18 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 	user, err := h.userService.Register(req.Email, req.Password, req.Name)
 	if err != nil {
+		if passwordRejected(c, err) {
+			return
+		}
 		response.Error(c, http.StatusBadRequest, "Registration failed", err.Error())
 		return
 	}
 
+	// The account exists either way; the user can ask for another email
+	if err := h.verificationService.SendVerification(user.ID); err != nil {
+		log.Println("Error sending verification email:", err)
+	}
+
 	response.Success(c, http.StatusCreated, "User registered successfully", user)
 }
 
@@ -81,7 +95,7 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 
 // UpdateProfile godoc
 // @Summary Update user profile
-// @Description Update the profile of the authenticated user
+// @Description Update the name of the authenticated user. The email address is changed with /users/change-email.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -99,7 +113,8 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	type updateProfileRequest struct {
-		Email string `json:"email" binding:"required,email"`
+		// Email may be sent back unchanged; changing it needs confirmation
+		Email string `json:"email" binding:"omitempty,email"`
 		Name  string `json:"name" binding:"required"`
 	}
 
@@ -109,7 +124,21 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	err := h.userService.UpdateUser(userID.(string), req.Email, req.Name)
+	user, err := h.userService.GetUserByID(userID.(string))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to update profile", err.Error())
+		return
+	}
+	if user == nil {
+		response.Error(c, http.StatusNotFound, "User not found", "user does not exist")
+		return
+	}
+	if req.Email != "" && req.Email != user.Email {
+		response.Error(c, http.StatusBadRequest, "Failed to update profile", "email changes must be confirmed, use /users/change-email")
+		return
+	}
+
+	err = h.userService.UpdateUser(user.ID, user.Email, req.Name)
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, "Failed to update profile", err.Error())
 		return
@@ -120,7 +149,7 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 // ChangePassword godoc
 // @Summary Change user password
-// @Description Change the password of the authenticated user
+// @Description Change the password of the authenticated user. Requires a token from /auth/reauthenticate. Other sessions are signed out and reset links invalidated.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -129,17 +158,19 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 // @Success 200 {object} response.Response "Password changed successfully"
 // @Failure 400 {object} response.Response "Invalid request parameters"
 // @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Reauthentication required"
 // @Router /api/v1/users/change-password [post]
 func (h *UserHandler) ChangePassword(c *gin.Context) {
-	userID, exists := c.Get("userID")
+	claims, exists := c.Get("claims")
 	if !exists {
 		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
 		return
 	}
+	accessClaims := claims.(*services.AccessClaims)
 
+	// The current password was checked by re-authenticating
 	type changePasswordRequest struct {
-		CurrentPassword string `json:"current_password" binding:"required"`
-		NewPassword    string `json:"new_password" binding:"required,min=6"`
+		NewPassword string `json:"new_password" binding:"required"`
 	}
 
 	var req changePasswordRequest
@@ -148,18 +179,25 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	// First verify the current password
-	_, err := h.userService.Login(userID.(string), req.CurrentPassword)
-	if err != nil {
-		response.Error(c, http.StatusBadRequest, "Invalid current password", err.Error())
-		return
-	}
-
-	// Update the password
-	if err := h.userService.UpdatePassword(userID.(string), req.NewPassword); err != nil {
+	if err := h.userService.ChangePassword(accessClaims.UserID, accessClaims.SessionID, req.NewPassword); err != nil {
+		if passwordRejected(c, err) {
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Failed to change password", err.Error())
 		return
 	}
 
 	response.Success(c, http.StatusOK, "Password changed successfully", nil)
 }
+
+// passwordRejected responds with the broken password policy rules if err is
+// a policy violation.
+func passwordRejected(c *gin.Context, err error) bool {
+	var policyErr *services.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+
+	response.Error(c, http.StatusUnprocessableEntity, "Password does not meet the policy", policyErr.Violations)
+	return true
+}
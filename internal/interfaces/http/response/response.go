@@ -6,6 +6,7 @@ type Response struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	Meta    interface{} `json:"meta,omitempty"`
 	Error   interface{} `json:"error,omitempty"`
 }
 
@@ -17,6 +18,17 @@ func Success(c *gin.Context, statusCode int, message string, data interface{}) {
 	})
 }
 
+// SuccessWithMeta responds like Success, adding metadata about the data such
+// as pagination details.
+func SuccessWithMeta(c *gin.Context, statusCode int, message string, data interface{}, meta interface{}) {
+	c.JSON(statusCode, Response{
+		Success: true,
+		Message: message,
+		Data:    data,
+		Meta:    meta,
+	})
+}
+
 func Error(c *gin.Context, statusCode int, message string, err interface{}) {
 	c.JSON(statusCode, Response{
 		Success: false,
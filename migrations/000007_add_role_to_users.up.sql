@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'viewer';
+
+UPDATE users SET role = 'admin' WHERE id = '00000000-0000-0000-0000-000000000001';
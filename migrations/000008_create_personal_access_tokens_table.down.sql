@@ -0,0 +1,3 @@
+ALTER TABLE signing_keys DROP COLUMN IF EXISTS retain_until;
+
+DROP TABLE IF EXISTS personal_access_tokens;
@@ -0,0 +1,2 @@
+ALTER TABLE products DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_scheduled_at;
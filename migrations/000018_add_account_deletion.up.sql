@@ -0,0 +1,9 @@
+-- Accounts scheduled for deletion are purged once the cooling-off period ends
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users(deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+
+-- Products stay in the catalogue when their owner's account is deleted
+ALTER TABLE products ADD COLUMN IF NOT EXISTS owner_id VARCHAR(36) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_owner_id ON products(owner_id);
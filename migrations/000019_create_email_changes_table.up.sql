@@ -0,0 +1,17 @@
+-- A requested email change takes effect once confirmed from the new address.
+-- The old address gets a link that cancels it or, once confirmed, undoes it.
+CREATE TABLE IF NOT EXISTS email_changes (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_email VARCHAR(255) NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    confirm_token_hash VARCHAR(64) NOT NULL UNIQUE,
+    revert_token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    revert_expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    confirmed_at TIMESTAMP,
+    reverted_at TIMESTAMP
+);
+
+CREATE INDEX idx_email_changes_user_id ON email_changes(user_id);
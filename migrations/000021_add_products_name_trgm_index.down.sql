@@ -0,0 +1,2 @@
+-- The extension is left installed, as other database objects may use it
+DROP INDEX IF EXISTS idx_products_name_trgm;
@@ -0,0 +1,4 @@
+-- Trigram index for fuzzy, typo tolerant autocomplete on product names
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops);
@@ -0,0 +1,29 @@
+-- Categories form a tree stored as materialized paths: a path lists the IDs
+-- from the root down to the category, as in /1/4/9/, so the descendants of a
+-- category are the rows whose path starts with its own.
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    parent_id INTEGER REFERENCES categories(id),
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    path TEXT NOT NULL UNIQUE,
+    depth INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Sibling names are unique; top-level categories are siblings of each other
+CREATE UNIQUE INDEX idx_categories_parent_name ON categories(COALESCE(parent_id, 0), LOWER(name));
+CREATE INDEX idx_categories_path_prefix ON categories(path text_pattern_ops);
+
+-- A product has at most one primary category and any number of secondary
+-- ones. Categories in use cannot be deleted.
+CREATE TABLE IF NOT EXISTS product_categories (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories(id),
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (product_id, category_id)
+);
+
+CREATE UNIQUE INDEX idx_product_categories_primary ON product_categories(product_id) WHERE is_primary;
+CREATE INDEX idx_product_categories_category_id ON product_categories(category_id);
@@ -0,0 +1,4 @@
+-- Revocations must outlive the user, or deleting an account would bring its
+-- revoked tokens back to life until they expire
+ALTER TABLE revoked_tokens DROP CONSTRAINT IF EXISTS revoked_tokens_user_id_fkey;
+ALTER TABLE user_token_revocations DROP CONSTRAINT IF EXISTS user_token_revocations_user_id_fkey;
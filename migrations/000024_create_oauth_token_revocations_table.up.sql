@@ -0,0 +1,12 @@
+-- Access tokens issued to an OAuth client on behalf of a user before
+-- revoked_before are rejected. An empty user_id covers every user, for
+-- deleted clients. Rows are kept until the tokens they cover have expired.
+CREATE TABLE IF NOT EXISTS oauth_token_revocations (
+    client_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL DEFAULT '',
+    revoked_before TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (client_id, user_id)
+);
+
+CREATE INDEX idx_oauth_token_revocations_expires_at ON oauth_token_revocations(expires_at);
@@ -0,0 +1,2 @@
+-- Login links are sent at most once a minute per user
+ALTER TABLE users ADD COLUMN IF NOT EXISTS magic_link_sent_at TIMESTAMP;
@@ -0,0 +1,2 @@
+-- Requested password reset links are sent at most once a minute per user
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_reset_sent_at TIMESTAMP;